@@ -0,0 +1,74 @@
+package vimebu
+
+import (
+	"fmt"
+	"log"
+)
+
+// WithLabelAllowlist declares the label names allowed on the metric with the provided name, for the [Builder] instances
+// acquired from the pool. The labels outside of the allowlist are skipped, and reported using the reporter set with
+// [WithLabelAllowlistReporter], or a log line written to [os.Stderr] if unset.
+//
+// Metrics without allowlist accept any label name.
+//
+// The allowlist is static, and applies in addition to the [MetricPolicy.AllowedLabels] of the policy set on the pool
+// (see [BuilderPool.SetPolicy]), which can be reloaded: a label is only added if both allow it. The allowlist is
+// checked first, so a label rejected by both is reported as outside of the allowlist.
+//
+// Calling it multiple times for the same metric name appends the label names.
+func WithLabelAllowlist(metric string, labels ...string) BuilderPoolOption {
+	return func(p *BuilderPool) {
+		if p.labelAllowlists == nil {
+			p.labelAllowlists = make(map[string]map[string]struct{})
+		}
+		allowlist, ok := p.labelAllowlists[metric]
+		if !ok {
+			allowlist = make(map[string]struct{}, len(labels))
+			p.labelAllowlists[metric] = allowlist
+		}
+		for _, label := range labels {
+			allowlist[label] = struct{}{}
+		}
+	}
+}
+
+// WithLabelAllowlistPanic makes the label methods of the [Builder] instances acquired from the pool panic
+// when a label outside of the allowlist of the metric is added, instead of skipping it, see [WithLabelAllowlist].
+//
+// As the panic happens while building the metric, possibly far from where the pool has been configured,
+// this option is meant to catch unexpected labels in tests, not to be used in production.
+func WithLabelAllowlistPanic() BuilderPoolOption {
+	return func(p *BuilderPool) {
+		p.panicOnLabelAllowlist = true
+	}
+}
+
+// WithLabelAllowlistReporter sets a function called with the metric and label names of each label skipped
+// because it is outside of the allowlist of the metric, see [WithLabelAllowlist].
+func WithLabelAllowlistReporter(report func(metric, label string)) BuilderPoolOption {
+	return func(p *BuilderPool) {
+		p.labelAllowlistReporter = report
+	}
+}
+
+// isAllowedLabelName checks if the label name is in the allowlist of the metric, if any.
+//
+// Panics if the label isn't allowed and the pool has been passed the [WithLabelAllowlistPanic] option.
+func (b *Builder) isAllowedLabelName(name string) bool {
+	if b.allowlist == nil {
+		return true
+	}
+	if _, ok := b.allowlist[name]; ok {
+		return true
+	}
+	metric := b.buf[:b.metricNameEnd()]
+	if b.pool.panicOnLabelAllowlist {
+		panic(fmt.Sprintf("vimebu: metric %q, label name %q isn't in the allowlist", metric, name))
+	}
+	if b.pool.labelAllowlistReporter != nil {
+		b.pool.labelAllowlistReporter(string(metric), name)
+		return false
+	}
+	log.Printf("vimebu: metric %q, label name %q isn't in the allowlist - skipping", metric, name)
+	return false
+}
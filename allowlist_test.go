@@ -0,0 +1,79 @@
+package vimebu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderPoolLabelAllowlist(t *testing.T) {
+	pool := NewBuilderPool(
+		WithLabelAllowlist("requests_total", "method"),
+		WithLabelAllowlist("requests_total", "status"),
+	)
+
+	logLines := captureLogOutput(func() {
+		metric := pool.Metric("requests_total").
+			LabelString("method", "GET").
+			LabelString("user", "gopher").
+			LabelInt("status", 200).
+			LabelBool("cached", true).
+			String()
+		require.Equal(t, `requests_total{method="GET",status="200"}`, metric)
+	})
+	require.Len(t, logLines, 2)
+	require.Contains(t, logLines[0], `metric "requests_total", label name "user" isn't in the allowlist - skipping`)
+
+	// Metrics without allowlist accept any label.
+	require.Equal(t, `other_total{user="gopher"}`, pool.Metric("other_total").LabelString("user", "gopher").String())
+}
+
+func TestBuilderPoolLabelAllowlistReporter(t *testing.T) {
+	var reported []string
+	pool := NewBuilderPool(
+		WithLabelAllowlist("requests_total", "method"),
+		WithLabelAllowlistReporter(func(metric, label string) {
+			reported = append(reported, metric+"/"+label)
+		}),
+	)
+
+	logLines := captureLogOutput(func() {
+		metric := pool.Metric("requests_total").
+			LabelString("method", "GET").
+			LabelString("user", "gopher").
+			String()
+		require.Equal(t, `requests_total{method="GET"}`, metric)
+	})
+	require.Empty(t, logLines)
+	require.Equal(t, []string{"requests_total/user"}, reported)
+}
+
+func TestBuilderPoolLabelAllowlistPanic(t *testing.T) {
+	pool := NewBuilderPool(WithLabelAllowlist("requests_total", "method"), WithLabelAllowlistPanic())
+
+	require.Equal(t, `requests_total{method="GET"}`, pool.Metric("requests_total").LabelString("method", "GET").String())
+	require.PanicsWithValue(t, `vimebu: metric "requests_total", label name "user" isn't in the allowlist`, func() {
+		b := pool.Acquire()
+		defer pool.Release(b)
+		b.Metric("requests_total").LabelString("method", "GET").LabelString("user", "gopher")
+	})
+}
+
+func TestBuilderPoolLabelAllowlistPolicy(t *testing.T) {
+	pool := NewBuilderPool(WithLabelAllowlist("requests_total", "method", "status"))
+	pool.SetPolicy(&Policy{Metrics: map[string]MetricPolicy{
+		"requests_total": {AllowedLabels: []string{"method", "user"}},
+	}})
+
+	logLines := captureLogOutput(func() {
+		metric := pool.Metric("requests_total").
+			LabelString("method", "GET").
+			LabelString("user", "gopher").
+			LabelInt("status", 200).
+			String()
+		require.Equal(t, `requests_total{method="GET"}`, metric)
+	})
+	require.Len(t, logLines, 2)
+	require.Contains(t, logLines[0], `label name "user" isn't in the allowlist - skipping`)
+	require.Contains(t, logLines[1], `label name "status" isn't allowed by the policy - skipping`)
+}
@@ -0,0 +1,164 @@
+package vimebu
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Presets of [Buckets], see [Builder.LabelBucket].
+var (
+	// ByteSizeBuckets maps sizes in bytes to the "0-1KiB", "1KiB-1MiB", "1MiB-1GiB" and "1GiB+" ranges.
+	ByteSizeBuckets = NewByteSizeBuckets(0, 1<<10, 1<<20, 1<<30)
+	// DurationBuckets maps durations in seconds to the "0s-10ms", "10ms-100ms", "100ms-1s", "1s-10s" and "10s+" ranges.
+	DurationBuckets = NewDurationBuckets(0, 10*time.Millisecond, 100*time.Millisecond, time.Second, 10*time.Second)
+	// PowerOfTwoBuckets maps counts to the "1-2", "2-4", ..., "512-1024" and "1024+" ranges.
+	PowerOfTwoBuckets = NewPowerOfTwoBuckets(0, 10)
+)
+
+// Buckets maps numeric values to range labels, allowing to label series with e.g. a size class
+// without exploding the label cardinality.
+//
+// Given the boundaries b0 < b1 < ... < bn, the ranges are "<b0", "b0-b1", ..., "bn+", where the
+// lower boundary of each range is inclusive and the upper boundary is exclusive.
+//
+// The range labels are computed once, when creating the [Buckets], so labelling a value doesn't allocate.
+//
+// The zero value has no boundaries, and maps all values to an empty label.
+type Buckets struct {
+	bounds []float64
+	labels []string
+}
+
+// NewBuckets creates new [Buckets] from the provided boundaries, formatted using format.
+//
+// If format is nil, the boundaries are formatted using their shortest representation (see [strconv.FormatFloat]).
+//
+// Panics if bounds is empty, or if it isn't sorted in strictly increasing order.
+func NewBuckets(bounds []float64, format func(float64) string) Buckets {
+	if len(bounds) == 0 {
+		panic("vimebu: NewBuckets has been passed empty boundaries")
+	}
+	for i := 1; i < len(bounds); i++ {
+		if !(bounds[i-1] < bounds[i]) {
+			panic("vimebu: NewBuckets has been passed boundaries not sorted in strictly increasing order")
+		}
+	}
+	if format == nil {
+		format = func(v float64) string {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	}
+	labels := make([]string, 0, len(bounds)+1)
+	labels = append(labels, "<"+format(bounds[0]))
+	for i := 1; i < len(bounds); i++ {
+		labels = append(labels, format(bounds[i-1])+"-"+format(bounds[i]))
+	}
+	labels = append(labels, format(bounds[len(bounds)-1])+"+")
+	return Buckets{bounds: slices.Clone(bounds), labels: labels}
+}
+
+// NewByteSizeBuckets creates new [Buckets] for sizes in bytes, formatted using IEC units (e.g. "1.5KiB").
+//
+// Panics if bounds is empty, or if it isn't sorted in strictly increasing order.
+func NewByteSizeBuckets(bounds ...uint64) Buckets {
+	fbounds := make([]float64, len(bounds))
+	for i, bound := range bounds {
+		fbounds[i] = float64(bound)
+	}
+	return NewBuckets(fbounds, formatByteSize)
+}
+
+// NewDurationBuckets creates new [Buckets] for durations, formatted using [time.Duration.String].
+// The values can be passed as [time.Duration] using [Buckets.LabelDuration] and [Builder.LabelDurationBucket],
+// or in seconds (see [time.Duration.Seconds]) using [Buckets.Label] and [Builder.LabelBucket].
+//
+// Panics if bounds is empty, or if it isn't sorted in strictly increasing order.
+func NewDurationBuckets(bounds ...time.Duration) Buckets {
+	fbounds := make([]float64, len(bounds))
+	for i, bound := range bounds {
+		fbounds[i] = bound.Seconds()
+	}
+	return NewBuckets(fbounds, func(v float64) string {
+		return time.Duration(v * float64(time.Second)).String()
+	})
+}
+
+// NewPowerOfTwoBuckets creates new [Buckets] with the boundaries 2^minExp, 2^(minExp+1), ..., 2^maxExp.
+//
+// Panics if minExp isn't lower than or equal to maxExp.
+func NewPowerOfTwoBuckets(minExp, maxExp int) Buckets {
+	if minExp > maxExp {
+		panic("vimebu: NewPowerOfTwoBuckets has been passed a minExp greater than maxExp")
+	}
+	bounds := make([]float64, 0, maxExp-minExp+1)
+	for exp := minExp; exp <= maxExp; exp++ {
+		bounds = append(bounds, math.Ldexp(1, exp))
+	}
+	return NewBuckets(bounds, nil)
+}
+
+// Label returns the label of the range containing value, or an empty string if value is NaN
+// or if the [Buckets] have no boundaries.
+func (bk Buckets) Label(value float64) string {
+	if len(bk.labels) == 0 || math.IsNaN(value) {
+		return ""
+	}
+	i := sort.Search(len(bk.bounds), func(i int) bool {
+		return bk.bounds[i] > value
+	})
+	return bk.labels[i]
+}
+
+// LabelDuration returns the label of the range containing the duration, for [Buckets] created
+// using [NewDurationBuckets], see [Buckets.Label].
+func (bk Buckets) LabelDuration(value time.Duration) string {
+	return bk.Label(value.Seconds())
+}
+
+var byteSizeUnits = [...]string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// formatByteSize formats a size in bytes using the largest fitting IEC unit.
+func formatByteSize(v float64) string {
+	unit := ""
+	for i := len(byteSizeUnits) - 1; i >= 0; i-- {
+		if size := math.Ldexp(1, 10*(i+1)); math.Abs(v) >= size {
+			v /= size
+			unit = byteSizeUnits[i]
+			break
+		}
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + unit
+}
+
+// LabelBucket adds a label with the range of [Buckets] containing value to the [Builder]
+// (e.g. "1KiB-1MiB" using [ByteSizeBuckets]).
+//
+// Durations must be provided in seconds when using [DurationBuckets] or [NewDurationBuckets]
+// (see [time.Duration.Seconds]), or using [Builder.LabelDurationBucket].
+//
+// NoOp if the label name is empty, if value is NaN, or if buckets have no boundaries.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelBucket(name string, value float64, buckets Buckets) *Builder {
+	label := buckets.Label(value)
+	if len(label) == 0 {
+		if !b.hasFlag(flagHasMetricName) {
+			panic("vimebu: can't add a label to a Builder with no metric name")
+		}
+		return b
+	}
+	return b.LabelString(name, label)
+}
+
+// LabelDurationBucket adds a label with the range of [Buckets] containing the duration to the [Builder]
+// (e.g. "100ms-1s" using [DurationBuckets]). The buckets must be created using [NewDurationBuckets].
+//
+// NoOp if the label name is empty, or if buckets have no boundaries.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelDurationBucket(name string, value time.Duration, buckets Buckets) *Builder {
+	return b.LabelBucket(name, value.Seconds(), buckets)
+}
@@ -0,0 +1,60 @@
+package vimebu
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuckets(t *testing.T) {
+	for _, tc := range []struct {
+		buckets  Buckets
+		value    float64
+		expected string
+	}{
+		{ByteSizeBuckets, -1, "<0"},
+		{ByteSizeBuckets, 0, "0-1KiB"},
+		{ByteSizeBuckets, 1023, "0-1KiB"},
+		{ByteSizeBuckets, 1024, "1KiB-1MiB"},
+		{ByteSizeBuckets, 5 << 20, "1MiB-1GiB"},
+		{ByteSizeBuckets, 1 << 40, "1GiB+"},
+		{NewByteSizeBuckets(512, 1536, 3<<30), 1000, "512-1.5KiB"},
+		{DurationBuckets, (50 * time.Millisecond).Seconds(), "10ms-100ms"},
+		{DurationBuckets, 0, "0s-10ms"},
+		{DurationBuckets, 42, "10s+"},
+		{PowerOfTwoBuckets, 0, "<1"},
+		{PowerOfTwoBuckets, 3, "2-4"},
+		{PowerOfTwoBuckets, 4096, "1024+"},
+		{NewBuckets([]float64{0.5, 1}, nil), 0.75, "0.5-1"},
+		{ByteSizeBuckets, math.NaN(), ""},
+		{Buckets{}, 42, ""},
+	} {
+		require.Equal(t, tc.expected, tc.buckets.Label(tc.value))
+	}
+	require.Equal(t, "10ms-100ms", DurationBuckets.LabelDuration(50*time.Millisecond))
+	require.Equal(t, "10s+", DurationBuckets.LabelDuration(time.Minute))
+}
+
+func TestNewBucketsInvalid(t *testing.T) {
+	require.Panics(t, func() { NewBuckets(nil, nil) })
+	require.Panics(t, func() { NewBuckets([]float64{1, 1}, nil) })
+	require.Panics(t, func() { NewBuckets([]float64{2, 1}, nil) })
+	require.Panics(t, func() { NewPowerOfTwoBuckets(3, 2) })
+}
+
+func TestBuilderLabelBucket(t *testing.T) {
+	metric := Metric("requests_total").
+		LabelBucket("payload", 2048, ByteSizeBuckets).
+		LabelBucket("latency", (250*time.Millisecond).Seconds(), DurationBuckets).
+		LabelBucket("nan", math.NaN(), ByteSizeBuckets).
+		LabelDurationBucket("timeout", 5*time.Second, DurationBuckets).
+		String()
+	require.Equal(t, `requests_total{payload="1KiB-1MiB",latency="100ms-1s",timeout="1s-10s"}`, metric)
+
+	require.Panics(t, func() {
+		var builder Builder
+		builder.LabelBucket("nan", math.NaN(), ByteSizeBuckets)
+	})
+}
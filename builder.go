@@ -1,8 +1,11 @@
 package vimebu
 
 import (
+	"encoding"
 	"fmt"
 	"log"
+	"math"
+	"slices"
 	"strconv"
 )
 
@@ -24,6 +27,8 @@ const (
 const (
 	flagHasMetricName = 1 << iota
 	flagHasLabel
+	flagReleaseOnString
+	flagDropped
 )
 
 // BuilderOption represents a modifier function that will apply a specific
@@ -52,17 +57,54 @@ func WithLabelNameMaxLen(maxLen int) BuilderOption {
 //   - [Builder.LabelErrorQuote]
 //   - [Builder.LabelNamedError]
 //   - [Builder.LabelNamedErrorQuote]
+//   - [Builder.LabelAppend]
+//   - [Builder.LabelTextAppender]
 //
 // Zero means no length limit.
 //
 // If the max len is exceeded for a label value, a log line containing the
-// reason will be written to [os.Stderr], and the label will be skipped.
+// reason will be written to [os.Stderr], and the label will be skipped,
+// unless the [WithLabelValueHashOverflow] option is set.
 func WithLabelValueMaxLen(maxLen int) BuilderOption {
 	return func(b *Builder) {
 		b.labelValueMaxLen = maxLen
 	}
 }
 
+// WithLabelValueHashOverflow replaces the label values exceeding the max len set using
+// [WithLabelValueMaxLen] with their hash (see [Builder.LabelHashed]), instead of skipping the label.
+//
+// If the max len is lower than 16, the hash is truncated to its leading hexadecimal digits.
+func WithLabelValueHashOverflow() BuilderOption {
+	return func(b *Builder) {
+		b.hashLongLabelValues = true
+	}
+}
+
+// WithFloatFormat sets the format and precision used to format the label values added using
+// [Builder.LabelFloat32] and [Builder.LabelFloat64] (see [strconv.FormatFloat]).
+//
+// Defaults to the 'f' format with a precision of -1, giving the shortest decimal representation
+// of the value without exponent. Large values may thus result in very long label values.
+func WithFloatFormat(format byte, precision int) BuilderOption {
+	return func(b *Builder) {
+		b.floatFormat = format
+		b.floatPrecision = precision
+	}
+}
+
+// WithErrorClassifier sets the [ErrorClassifier] used by the following methods :
+//
+//   - [Builder.LabelErrorClass]
+//   - [Builder.LabelNamedErrorClass]
+//
+// Defaults to the [DefaultErrorClassifier].
+func WithErrorClassifier(classifier *ErrorClassifier) BuilderOption {
+	return func(b *Builder) {
+		b.errorClassifier = classifier
+	}
+}
+
 // Builder is used to efficiently build a VictoriaMetrics metric.
 //
 // It is forbidden copying [Builder] instances.
@@ -79,12 +121,32 @@ func WithLabelValueMaxLen(maxLen int) BuilderOption {
 type Builder struct {
 	_ noCopy
 
-	pool *BuilderPool
+	pool      *BuilderPool
+	policy    *metricPolicy
+	allowlist map[string]struct{}
+
+	buf    []byte
+	labels []labelSpan
+
+	labelNameMaxLen     int
+	labelValueMaxLen    int
+	hashLongLabelValues bool
+
+	maxLabels    int
+	maxSeriesLen int
+	limitPolicy  LimitPolicy
+
+	reservedLabelPolicy   ReservedLabelPolicy
+	reservedLabelReporter func(metric, label string)
+
+	emptyLabelPlaceholder string
+	labelPlaceholders     Labels
 
-	buf []byte
+	floatFormat    byte
+	floatPrecision int
 
-	labelNameMaxLen  int
-	labelValueMaxLen int
+	errorClassifier *ErrorClassifier
+	attrGroupSep    string
 
 	flags uint8
 }
@@ -98,18 +160,34 @@ func (b *Builder) hasFlag(flag uint8) bool {
 }
 
 // Reset zeroes out a [Builder] instance for reuse.
+//
+// A [Builder] acquired from a [BuilderPool] stays attached to it, and keeps applying its configuration.
 func (b *Builder) Reset() {
-	b.pool = nil
+	b.policy = nil
+	b.allowlist = nil
 	b.buf = b.buf[:0]
+	b.labels = b.labels[:0]
 	b.flags = 0
 	b.labelNameMaxLen = 0
 	b.labelValueMaxLen = 0
+	b.hashLongLabelValues = false
+	b.maxLabels = 0
+	b.maxSeriesLen = 0
+	b.limitPolicy = 0
+	b.reservedLabelPolicy = 0
+	b.reservedLabelReporter = nil
+	b.emptyLabelPlaceholder = ""
+	b.labelPlaceholders = b.labelPlaceholders[:0]
+	b.floatFormat = 0
+	b.floatPrecision = 0
+	b.errorClassifier = nil
+	b.attrGroupSep = ""
 }
 
 // Metric acquires and returns a zeroed-out [Builder] instance from the
 // default builder pool and sets the metric's name.
 func Metric(name string, options ...BuilderOption) *Builder {
-	return defaultBuilderPool.Metric(name, options...)
+	return DefaultBuilderPool().Metric(name, options...)
 }
 
 // Metric sets the metric's name of the [Builder].
@@ -127,6 +205,13 @@ func (b *Builder) Metric(name string, options ...BuilderOption) *Builder {
 	for _, applyOption := range options {
 		applyOption(b)
 	}
+	if b.pool != nil {
+		b.allowlist = b.pool.labelAllowlists[name]
+		b.applyPolicy(name)
+	}
+	if !b.isWithinMetricNameLimit(name) {
+		b.setFlag(flagDropped)
+	}
 
 	b.buf = append(b.buf, name...)
 	b.setFlag(flagHasMetricName)
@@ -135,7 +220,7 @@ func (b *Builder) Metric(name string, options ...BuilderOption) *Builder {
 
 // LabelString adds a label with a value of type string to the [Builder].
 //
-// NoOp if the label name or value are empty.
+// NoOp if the label name is empty, or if the value is empty and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelString(name, value string) *Builder {
@@ -145,7 +230,7 @@ func (b *Builder) LabelString(name, value string) *Builder {
 // LabelStringQuote adds a label with a value of type string to the [Builder].
 // Quotes inside label value will be escaped using [strconv.AppendQuote].
 //
-// NoOp if the label name or value are empty.
+// NoOp if the label name is empty, or if the value is empty and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelStringQuote(name, value string) *Builder {
@@ -156,39 +241,60 @@ func (b *Builder) labelString(name, value string, escapeQuotes bool) *Builder {
 	if !b.hasFlag(flagHasMetricName) {
 		panic("vimebu: can't add a label to a Builder with no metric name")
 	}
-	if !b.isValidLabelName(name) || !b.isValidLabelValue(name, value) {
+	if !b.isValidLabelName(name) {
 		return b
 	}
-	b.buf = appendLabel(b.buf, name, func(dst []byte) []byte {
+	return b.labelValue(name, value, escapeQuotes)
+}
+
+// labelValue normalizes, redacts and validates the value of a label whose name has already been validated,
+// then adds it to the [Builder].
+func (b *Builder) labelValue(name, value string, escapeQuotes bool) *Builder {
+	value, ok := b.rewriteLabelValue(name, value)
+	if !ok {
+		return b
+	}
+	if len(value) == 0 {
+		value = b.labelPlaceholder(name)
+	}
+	if b.overflowsLabelValue(len(value)) {
+		b.addLabel(name, func(dst []byte) []byte {
+			return b.appendOverflowHash(dst, hashLabelValue(value))
+		}, true)
+		return b
+	}
+	if !b.isValidLabelValue(name, value) {
+		return b
+	}
+	b.addLabel(name, func(dst []byte) []byte {
 		if !escapeQuotes { // Fast path for when explicit quote escaping is not required.
 			return append(dst, value...)
 		}
 		return strconv.AppendQuote(dst, value)
 	}, !escapeQuotes)
-	b.setFlag(flagHasLabel)
 	return b
 }
 
 // LabelError adds a label with a value implementing the error interface to the [Builder].
 //
-// NoOp if the label name is empty, or if err is nil.
+// NoOp if the label name is empty, or if err is nil and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelError(err error) *Builder {
 	if err == nil {
-		return b
+		return b.labelNil(errorLabelName)
 	}
 	return b.LabelString(errorLabelName, err.Error())
 }
 
 // LabelNamedError adds a label with a value implementing the error interface to the [Builder].
 //
-// NoOp if the label name is empty, or if err is nil.
+// NoOp if the label name is empty, or if err is nil and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelNamedError(name string, err error) *Builder {
 	if err == nil {
-		return b
+		return b.labelNil(name)
 	}
 	return b.LabelString(name, err.Error())
 }
@@ -196,12 +302,12 @@ func (b *Builder) LabelNamedError(name string, err error) *Builder {
 // LabelErrorQuote adds a label with a value implementing the error interface to the [Builder].
 // Quotes inside label value will be escaped using [strconv.AppendQuote].
 //
-// NoOp if the label name is empty, or if err is nil.
+// NoOp if the label name is empty, or if err is nil and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelErrorQuote(err error) *Builder {
 	if err == nil {
-		return b
+		return b.labelNil(errorLabelName)
 	}
 	return b.LabelStringQuote(errorLabelName, err.Error())
 }
@@ -209,16 +315,45 @@ func (b *Builder) LabelErrorQuote(err error) *Builder {
 // LabelNamedErrorQuote adds a label with a value implementing the error interface to the [Builder].
 // Quotes inside label value will be escaped using [strconv.AppendQuote].
 //
-// NoOp if the label name is empty, or if err is nil.
+// NoOp if the label name is empty, or if err is nil and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelNamedErrorQuote(name string, err error) *Builder {
 	if err == nil {
-		return b
+		return b.labelNil(name)
 	}
 	return b.LabelStringQuote(name, err.Error())
 }
 
+// LabelErrorClass adds a label with the class of an error to the [Builder].
+// The class is computed by the [ErrorClassifier] set with [WithErrorClassifier],
+// or by the [DefaultErrorClassifier], instead of using the error message.
+//
+// NoOp if err is nil and no placeholder is set (see [WithEmptyLabelPlaceholder]).
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelErrorClass(err error) *Builder {
+	return b.LabelNamedErrorClass(errorLabelName, err)
+}
+
+// LabelNamedErrorClass adds a label with the class of an error to the [Builder].
+// The class is computed by the [ErrorClassifier] set with [WithErrorClassifier],
+// or by the [DefaultErrorClassifier], instead of using the error message.
+//
+// NoOp if the label name is empty, or if err is nil and no placeholder is set (see [WithEmptyLabelPlaceholder]).
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelNamedErrorClass(name string, err error) *Builder {
+	if err == nil {
+		return b.labelNil(name)
+	}
+	classifier := b.errorClassifier
+	if classifier == nil {
+		classifier = DefaultErrorClassifier
+	}
+	return b.LabelStringQuote(name, classifier.Classify(err))
+}
+
 // LabelBool adds a label with a value of type bool to the [Builder].
 //
 // NoOp if the label name is empty.
@@ -279,10 +414,9 @@ func (b *Builder) LabelUint64(name string, value uint64) *Builder {
 	if !b.isValidLabelName(name) {
 		return b
 	}
-	b.buf = appendLabel(b.buf, name, func(dst []byte) []byte {
+	b.addLabel(name, func(dst []byte) []byte {
 		return strconv.AppendUint(dst, value, base10)
 	}, true)
-	b.setFlag(flagHasLabel)
 	return b
 }
 
@@ -334,15 +468,16 @@ func (b *Builder) LabelInt64(name string, value int64) *Builder {
 	if !b.isValidLabelName(name) {
 		return b
 	}
-	b.buf = appendLabel(b.buf, name, func(dst []byte) []byte {
+	b.addLabel(name, func(dst []byte) []byte {
 		return strconv.AppendInt(dst, value, base10)
 	}, true)
-	b.setFlag(flagHasLabel)
 	return b
 }
 
 // LabelFloat32 adds a label with a value of type float32 to the [Builder].
 //
+// The value is formatted using the format set with [WithFloatFormat], see [Builder.LabelFloat64].
+//
 // NoOp if the label name is empty.
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
@@ -352,31 +487,69 @@ func (b *Builder) LabelFloat32(name string, value float32) *Builder {
 
 // LabelFloat64 adds a label with a value of type float64 to the [Builder].
 //
+// The value is formatted using the format set with [WithFloatFormat], or in its shortest
+// decimal representation without exponent if unset. Special values are formatted as "NaN",
+// "+Inf" and "-Inf".
+//
 // NoOp if the label name is empty.
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelFloat64(name string, value float64) *Builder {
+	if b.floatFormat == 0 {
+		return b.LabelFloat64Format(name, value, floatFormattingVerb, floatShortestPrecision)
+	}
+	return b.LabelFloat64Format(name, value, b.floatFormat, b.floatPrecision)
+}
+
+// LabelFloat32Format adds a label with a value of type float32 to the [Builder],
+// formatted using the provided format and precision, see [Builder.LabelFloat64Format].
+//
+// NoOp if the label name is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelFloat32Format(name string, value float32, format byte, precision int) *Builder {
+	return b.LabelFloat64Format(name, float64(value), format, precision)
+}
+
+// LabelFloat64Format adds a label with a value of type float64 to the [Builder],
+// formatted using the provided format and precision (see [strconv.FormatFloat]).
+// Special values are formatted as "NaN", "+Inf" and "-Inf".
+//
+// For example, the 'g' format with a precision of -1 gives the shortest representation
+// of the value, using an exponent for large ones (e.g. "1e+300"). The 'f' format with a
+// precision of 2 rounds the value to 2 decimal digits (e.g. "3.14").
+//
+// NoOp if the label name is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelFloat64Format(name string, value float64, format byte, precision int) *Builder {
 	if !b.hasFlag(flagHasMetricName) {
 		panic("vimebu: can't add a label to a Builder with no metric name")
 	}
 	if !b.isValidLabelName(name) {
 		return b
 	}
-	b.buf = appendLabel(b.buf, name, func(dst []byte) []byte {
-		return strconv.AppendFloat(dst, value, floatFormattingVerb, floatShortestPrecision, floatBitSize)
+	b.addLabel(name, func(dst []byte) []byte {
+		return appendFloat(dst, value, format, precision)
 	}, true)
-	b.setFlag(flagHasLabel)
 	return b
 }
 
 // LabelStringer adds a label with a value implementing the [fmt.Stringer] interface to the [Builder].
 //
-// NoOp if the label name is empty, if value is nil, or if the value.String() method call returns an empty string.
+// If value also implements [LabelAppender], it is used instead of the value.String() method
+// to avoid allocating a string.
+//
+// NoOp if the label name is empty, or if value is nil or the value.String() method call returns an empty string
+// and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelStringer(name string, value fmt.Stringer) *Builder {
 	if value == nil {
-		return b
+		return b.labelNil(name)
+	}
+	if appender, ok := value.(LabelAppender); ok {
+		return b.LabelAppend(name, appender)
 	}
 	return b.LabelString(name, value.String())
 }
@@ -384,28 +557,93 @@ func (b *Builder) LabelStringer(name string, value fmt.Stringer) *Builder {
 // LabelStringerQuote adds a label with a value implementing the [fmt.Stringer] interface to the [Builder].
 // Quotes inside label value will be escaped using [strconv.AppendQuote].
 //
-// NoOp if the label name is empty, if value is nil, or if the value.String() method call returns an empty string.
+// NoOp if the label name is empty, or if value is nil or the value.String() method call returns an empty string
+// and no placeholder is set (see [WithEmptyLabelPlaceholder]).
 //
 // Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
 func (b *Builder) LabelStringerQuote(name string, value fmt.Stringer) *Builder {
 	if value == nil {
-		return b
+		return b.labelNil(name)
 	}
 	return b.LabelStringQuote(name, value.String())
 }
 
+// LabelAppender is implemented by values able to append their label value representation
+// to a buffer, to be added to a [Builder] without allocating an intermediate string.
+//
+// The appended representation must not contain double quotes, as it won't be escaped.
+type LabelAppender interface {
+	// AppendLabelValue appends the label value representation to dst and returns the extended buffer.
+	AppendLabelValue(dst []byte) []byte
+}
+
+// LabelAppend adds a label with a value implementing the [LabelAppender] interface to the [Builder].
+// The value is appended directly into the Builder's buffer.
+//
+// NoOp if the label name is empty, if value is nil, or if value appends nothing.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelAppend(name string, value LabelAppender) *Builder {
+	if value == nil {
+		return b
+	}
+	return b.labelAppender(name, func(dst []byte) ([]byte, error) {
+		return value.AppendLabelValue(dst), nil
+	})
+}
+
+// LabelTextAppender adds a label with a value implementing the [encoding.TextAppender] interface to the [Builder],
+// such as [net.IP], [netip.Addr] or [time.Time]. The value is appended directly into the Builder's buffer.
+//
+// The appended text must not contain double quotes, as it won't be escaped.
+//
+// NoOp if the label name is empty, if value is nil, or if value appends nothing.
+// If value.AppendText returns an error, a log line containing the reason will be written to [os.Stderr],
+// and the label will be skipped.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelTextAppender(name string, value encoding.TextAppender) *Builder {
+	if value == nil {
+		return b
+	}
+	return b.labelAppender(name, value.AppendText)
+}
+
 // String builds the complete metric by returning the accumulated string.
+//
+// Returns an empty string if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) String() string {
-	if b.pool != nil {
+	s, _ := b.build(nil)
+	return s
+}
+
+// build builds the complete metric, and reports whether it has been kept.
+//
+// The labels whose name is in reserved are handled according to [WithReservedLabelPolicy].
+func (b *Builder) build(reserved []string) (string, bool) {
+	if b.hasFlag(flagReleaseOnString) {
 		defer b.pool.Release(b)
 	}
 	if !b.hasFlag(flagHasMetricName) {
-		return ""
+		return "", true
+	}
+	if b.hasFlag(flagDropped) {
+		return "", false
+	}
+	if b.pool != nil && len(b.pool.relabelRules) > 0 && !b.relabel() {
+		return "", false
+	}
+	if len(reserved) > 0 {
+		b.handleReservedLabels(reserved)
 	}
 	if b.hasFlag(flagHasLabel) {
 		b.buf = append(b.buf, rightBracketByte)
 	}
-	return string(b.buf)
+	s := string(b.buf)
+	if b.policy != nil && !b.policy.admitSeries(s) {
+		return "", false
+	}
+	return s, true
 }
 
 // isValidLabelName checks if the provided label name is valid.
@@ -415,6 +653,9 @@ func (b *Builder) String() string {
 // If the [Builder] was passed the [WithLabelNameMaxLen] option, the
 // label name len must also be less than the provided max len value.
 //
+// If the [Builder] was acquired from a [BuilderPool], the label name must also be
+// allowed by the allowlist (see [WithLabelAllowlist]) and the policy of the metric.
+//
 // In case of an invalid label name, a log line containing the reasons will be written to [os.Stderr].
 func (b *Builder) isValidLabelName(name string) bool {
 	ln := len(name)
@@ -426,6 +667,13 @@ func (b *Builder) isValidLabelName(name string) bool {
 		log.Printf("vimebu: metric %q, label name %q len exceeds set limit of %d - skipping", b.buf, name, b.labelNameMaxLen)
 		return false
 	}
+	if !b.isAllowedLabelName(name) {
+		return false
+	}
+	if b.policy != nil && !b.policy.allowsLabel(name) {
+		log.Printf("vimebu: metric %q, label name %q isn't allowed by the policy - skipping", b.buf, name)
+		return false
+	}
 	return true
 }
 
@@ -444,12 +692,122 @@ func (b *Builder) isValidLabelValue(name, value string) bool {
 		return false
 	}
 	if b.labelValueMaxLen > 0 && lv > b.labelValueMaxLen {
-		log.Printf("vimebu: metric %q, label name %q, label value %q len exceeds set limit of %d - skipping", b.buf, name, value, b.labelNameMaxLen)
+		log.Printf("vimebu: metric %q, label name %q, label value %q len exceeds set limit of %d - skipping", b.buf, name, value, b.labelValueMaxLen)
 		return false
 	}
 	return true
 }
 
+// overflowsLabelValue reports whether a label value of length n should be replaced by its hash,
+// see [WithLabelValueHashOverflow].
+func (b *Builder) overflowsLabelValue(n int) bool {
+	return b.hashLongLabelValues && b.labelValueMaxLen > 0 && n > b.labelValueMaxLen
+}
+
+// appendOverflowHash appends h to dst, keeping only its leading hexadecimal digits
+// if the max len is lower than 16, so the hash never exceeds it.
+func (b *Builder) appendOverflowHash(dst []byte, h uint64) []byte {
+	start := len(dst)
+	dst = appendLabelValueHash(dst, h)
+	if len(dst)-start > b.labelValueMaxLen {
+		dst = dst[:start+b.labelValueMaxLen]
+	}
+	return dst
+}
+
+// rewriteLabelValue applies the normalizers and redaction rules of the pool to value, if any.
+//
+// Returns false if the label must be skipped.
+func (b *Builder) rewriteLabelValue(name, value string) (string, bool) {
+	if b.pool == nil {
+		return value, true
+	}
+	value = b.pool.normalize(name, value)
+	if len(b.pool.redactionRules) == 0 {
+		return value, true
+	}
+	return b.redactLabelValue(name, value)
+}
+
+// labelAppender adds a label whose value is written directly into the buffer by appender.
+//
+// The label is rolled back if appender fails, or if the appended value is invalid.
+func (b *Builder) labelAppender(name string, appender func([]byte) ([]byte, error)) *Builder {
+	if !b.hasFlag(flagHasMetricName) {
+		panic("vimebu: can't add a label to a Builder with no metric name")
+	}
+	if !b.isValidLabelName(name) {
+		return b
+	}
+	if b.pool != nil && b.pool.rewritesLabelValue(name) {
+		// The value is appended to the tail of the buffer, then goes through the string path.
+		start := len(b.buf)
+		buf, err := appender(b.buf)
+		if err != nil {
+			log.Printf("vimebu: metric %q, label name %q, failed to append label value: %v - skipping", b.buf[:start], name, err)
+			return b
+		}
+		value := string(buf[start:])
+		b.buf = buf[:start]
+		return b.labelValue(name, value, false)
+	}
+	return b.appendLabelValue(name, appender)
+}
+
+// appendLabelValue adds a label whose name has already been validated, and whose value is written directly
+// into the buffer by appender, without going through the normalizers and redaction rules of the pool.
+//
+// The label is rolled back if appender fails, or if the appended value is invalid.
+func (b *Builder) appendLabelValue(name string, appender func([]byte) ([]byte, error)) *Builder {
+	start := len(b.buf)
+	b.buf = append(b.buf, sep(b.buf))
+	b.buf = append(b.buf, name...)
+	b.buf = append(b.buf, equalByte, doubleQuotesByte)
+	valueStart := len(b.buf)
+	buf, err := appender(b.buf)
+	if err != nil {
+		log.Printf("vimebu: metric %q, label name %q, failed to append label value: %v - skipping", b.buf[:start], name, err)
+		b.buf = b.buf[:start]
+		return b
+	}
+	b.buf = buf
+	if b.overflowsLabelValue(len(b.buf) - valueStart) {
+		h := hashLabelValue(b.buf[valueStart:])
+		b.buf = b.appendOverflowHash(b.buf[:valueStart], h)
+		b.buf = append(b.buf, doubleQuotesByte)
+		b.recordLabel(start, len(name), false)
+		return b
+	}
+	if len(b.buf) == valueStart { // Let the string path write the placeholder, or report the empty value.
+		b.buf = b.buf[:start]
+		return b.labelValue(name, "", true)
+	}
+	// Only convert the value to a string when it is invalid, to keep the happy path allocation free.
+	if lv := len(b.buf) - valueStart; b.labelValueMaxLen > 0 && lv > b.labelValueMaxLen {
+		value := string(b.buf[valueStart:])
+		b.buf = b.buf[:start]
+		b.isValidLabelValue(name, value)
+		return b
+	}
+	b.buf = append(b.buf, doubleQuotesByte)
+	b.recordLabel(start, len(name), false)
+	return b
+}
+
+// appendFloat appends the formatted value to dst, using the exposition format
+// representation for special values.
+func appendFloat(dst []byte, value float64, format byte, precision int) []byte {
+	switch {
+	case math.IsNaN(value):
+		return append(dst, "NaN"...)
+	case math.IsInf(value, 1):
+		return append(dst, "+Inf"...)
+	case math.IsInf(value, -1):
+		return append(dst, "-Inf"...)
+	}
+	return strconv.AppendFloat(dst, value, format, precision, floatBitSize)
+}
+
 // appendSep decides whether to insert a comma or opening brace based on the
 // current buffer tail.
 func sep(dst []byte) byte {
@@ -472,3 +830,78 @@ func appendLabel(dst []byte, name string, appender func([]byte) []byte, manualQu
 	}
 	return appender(dst)
 }
+
+// metricNameEnd returns the index of the end of the metric name in the buffer.
+func (b *Builder) metricNameEnd() int {
+	if len(b.labels) > 0 {
+		return b.labels[0].start
+	}
+	return len(b.buf)
+}
+
+// removeLabelAt removes the i-th label from the buffer.
+func (b *Builder) removeLabelAt(i int) {
+	span := b.labels[i]
+	if i == 0 && len(b.labels) > 1 { // The following label becomes the first one.
+		b.buf[b.labels[1].start] = leftBracketByte
+	}
+	b.spliceLabels(i, span.start, span.end, nil)
+	b.labels = slices.Delete(b.labels, i, i+1)
+	if len(b.labels) == 0 {
+		b.flags &^= flagHasLabel
+	}
+}
+
+// renameLabelAt renames the i-th label of the buffer.
+func (b *Builder) renameLabelAt(i int, name string) {
+	span := b.labels[i]
+	b.spliceLabels(i, span.start+1, span.nameEnd, []byte(name))
+}
+
+// spliceLabels replaces the bytes of the buffer between from and to, which must be part of the i-th label,
+// with data, and shifts the spans of the labels.
+func (b *Builder) spliceLabels(i, from, to int, data []byte) {
+	b.buf = slices.Replace(b.buf, from, to, data...)
+	delta := len(data) - (to - from)
+	span := &b.labels[i]
+	if span.nameEnd >= to {
+		span.nameEnd += delta
+	}
+	span.end += delta
+	for j := i + 1; j < len(b.labels); j++ {
+		b.labels[j].start += delta
+		b.labels[j].nameEnd += delta
+		b.labels[j].end += delta
+	}
+}
+
+// labelSpan locates a label added to a [Builder] in its buffer.
+type labelSpan struct {
+	start   int  // Index of the separator preceding the label name.
+	nameEnd int  // Index of the equal sign following the label name.
+	end     int  // Index following the closing double quote of the label value.
+	quoted  bool // Whether the label value was quoted using strconv.AppendQuote.
+}
+
+// addLabel appends a label to the buffer using [appendLabel], and records its span.
+func (b *Builder) addLabel(name string, appender func([]byte) []byte, manualQuote bool) {
+	start := len(b.buf)
+	b.buf = appendLabel(b.buf, name, appender, manualQuote)
+	b.recordLabel(start, len(name), !manualQuote)
+}
+
+// recordLabel records the span of the label starting at start and ending at the end of the buffer.
+//
+// The label is rolled back if it exceeds the limits set using [WithMaxLabels] and [WithMaxSeriesLen].
+func (b *Builder) recordLabel(start, nameLen int, quoted bool) {
+	if (b.maxLabels > 0 || b.maxSeriesLen > 0) && !b.isWithinLimits(start, nameLen, quoted) {
+		return
+	}
+	b.labels = append(b.labels, labelSpan{
+		start:   start,
+		nameEnd: start + 1 + nameLen,
+		end:     len(b.buf),
+		quoted:  quoted,
+	})
+	b.setFlag(flagHasLabel)
+}
@@ -1,19 +1,72 @@
 package vimebu
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+)
 
 const (
 	// smallBufferSize is an initial allocation minimal capacity.
 	smallBufferSize int = 64
 )
 
-var (
-	defaultBuilderPool = NewBuilderPool()
-)
+// defaultBuilderPool holds the pool used by the package level functions, see [DefaultBuilderPool].
+var defaultBuilderPool atomic.Pointer[BuilderPool]
+
+func init() {
+	defaultBuilderPool.Store(NewBuilderPool())
+}
+
+// DefaultBuilderPool returns the [BuilderPool] used by the package level functions, such as [Metric],
+// [MetricCtx] and [AcquireBuilder], and by the helpers instrumenting functions, HTTP servers and clients,
+// SQL drivers and slog handlers provided by the package.
+//
+// It can be used to apply a [Policy] to all of them, see [BuilderPool.SetPolicy] and [BuilderPool.WatchPolicyFile].
+func DefaultBuilderPool() *BuilderPool {
+	return defaultBuilderPool.Load()
+}
+
+// SetDefaultBuilderPool replaces the [BuilderPool] used by the package level functions and helpers,
+// see [DefaultBuilderPool]. It allows configuring them with a pool created using [NewBuilderPool] and options
+// such as [WithLabelNormalizers], [WithLabelRedaction], [WithRelabelConfigs] or [WithLabelAllowlist].
+//
+// It should be called during the initialization of the program: the metrics already built, and the helpers
+// holding them, aren't affected.
+//
+// Panics if p is nil.
+func SetDefaultBuilderPool(p *BuilderPool) {
+	if p == nil {
+		panic("vimebu: SetDefaultBuilderPool has been passed a nil BuilderPool")
+	}
+	defaultBuilderPool.Store(p)
+}
+
+// BuilderPoolOption represents a modifier function that will apply a specific
+// configuration to a [BuilderPool] instance.
+type BuilderPoolOption func(*BuilderPool)
+
+// WithLabelNormalizers registers normalizers applied, in order, to the values of the labels
+// with the provided name, before they are added to the [Builder] instances acquired from the pool.
+//
+// Applies to label values added using [Builder.LabelString] and the methods relying on it,
+// such as [Builder.LabelStringQuote], [Builder.LabelStringer] or [Builder.LabelError], and to the values
+// written by [Builder.LabelAppend], [Builder.LabelTextAppender] and the methods relying on them,
+// which are then converted to a string. [Builder.LabelHashed] applies them to the value before hashing it.
+// Doesn't apply to numeric label values.
+//
+// Calling it multiple times for the same label name appends the normalizers.
+func WithLabelNormalizers(name string, normalizers ...Normalizer) BuilderPoolOption {
+	return func(p *BuilderPool) {
+		if p.normalizers == nil {
+			p.normalizers = make(map[string][]Normalizer)
+		}
+		p.normalizers[name] = append(p.normalizers[name], normalizers...)
+	}
+}
 
 // NewBuilderPool creates a new [BuilderPool] instance.
-func NewBuilderPool() *BuilderPool {
-	return &BuilderPool{
+func NewBuilderPool(options ...BuilderPoolOption) *BuilderPool {
+	p := &BuilderPool{
 		pool: sync.Pool{
 			New: func() any {
 				return &Builder{
@@ -22,12 +75,28 @@ func NewBuilderPool() *BuilderPool {
 			},
 		},
 	}
+	for _, applyOption := range options {
+		applyOption(p)
+	}
+	return p
 }
 
 // BuilderPool is a strongly typed wrapper around a [sync.Pool], specifically used to
 // store and retrieve [Builder] instances.
+//
+// Its configuration applies to all the [Builder] instances acquired from it.
 type BuilderPool struct {
 	pool sync.Pool
+
+	normalizers    map[string][]Normalizer
+	redactionRules []RedactionRule
+	relabelRules   []relabelRule
+
+	labelAllowlists        map[string]map[string]struct{}
+	panicOnLabelAllowlist  bool
+	labelAllowlistReporter func(metric, label string)
+
+	policy atomic.Pointer[policyState]
 }
 
 // Acquire returns an empty [Builder] instance from the specified pool.
@@ -35,7 +104,9 @@ type BuilderPool struct {
 // Release the [Builder] with [BuilderPool.Release] after the [Builder] is no longer needed.
 // This allows reducing GC load.
 func (p *BuilderPool) Acquire() *Builder {
-	return p.pool.Get().(*Builder)
+	b := p.pool.Get().(*Builder)
+	b.pool = p
+	return b
 }
 
 // AcquireBuilder returns an empty [Builder] instance from the default builder pool.
@@ -43,7 +114,7 @@ func (p *BuilderPool) Acquire() *Builder {
 // Release the [Builder] with [ReleaseBuilder] after the [Builder] is no longer needed.
 // This allows reducing GC load.
 func AcquireBuilder() *Builder {
-	return defaultBuilderPool.Acquire()
+	return DefaultBuilderPool().Acquire()
 }
 
 // Release releases the [Builder] acquired via [BuilderPool.Acquire] to the specified pool.
@@ -52,6 +123,7 @@ func AcquireBuilder() *Builder {
 // may occur.
 func (p *BuilderPool) Release(b *Builder) {
 	b.Reset()
+	b.pool = nil
 	p.pool.Put(b)
 }
 
@@ -60,13 +132,30 @@ func (p *BuilderPool) Release(b *Builder) {
 // The released [Builder] mustn't be used after releasing it, otherwise data races
 // may occur.
 func ReleaseBuilder(b *Builder) {
-	defaultBuilderPool.Release(b)
+	DefaultBuilderPool().Release(b)
 }
 
 // Metric acquires and returns a zeroed-out [Builder] instance from the
 // specified pool and sets the metric's name.
 func (p *BuilderPool) Metric(name string, options ...BuilderOption) *Builder {
 	b := p.Acquire()
-	b.pool = p
+	b.setFlag(flagReleaseOnString)
 	return b.Metric(name, options...)
 }
+
+// rewritesLabelValue reports whether the values of the labels with the provided name
+// can be rewritten by normalizers or redaction rules.
+func (p *BuilderPool) rewritesLabelValue(name string) bool {
+	return len(p.redactionRules) > 0 || len(p.normalizers[name]) > 0
+}
+
+// normalize applies the normalizers registered for the label name to value.
+func (p *BuilderPool) normalize(name, value string) string {
+	if len(p.normalizers) == 0 { // Fast path for pools without normalizers.
+		return value
+	}
+	for _, normalizer := range p.normalizers[name] {
+		value = normalizer(value)
+	}
+	return value
+}
@@ -2,11 +2,15 @@ package vimebu
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net/netip"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
@@ -356,8 +360,77 @@ func TestBuilderOptionsWithLabelValueMaxLen(t *testing.T) {
 	require.Len(t, logLines, 3) // One log line for each label value exceeding the limit of 5 bytes, thus getting skipped.
 }
 
+type appenderValue struct {
+	value string
+}
+
+func (v appenderValue) String() string {
+	panic("appenderValue.String must not be called when LabelAppender is implemented")
+}
+
+func (v appenderValue) AppendLabelValue(dst []byte) []byte {
+	return append(dst, v.value...)
+}
+
+type failingTextAppender struct{}
+
+func (failingTextAppender) AppendText(dst []byte) ([]byte, error) {
+	return append(dst, "partial"...), errors.New("boom")
+}
+
+func TestBuilderLabelAppender(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("test_appender", WithLabelValueMaxLen(12)).
+			LabelTextAppender("ip", netip.MustParseAddr("192.168.0.1")).
+			LabelTextAppender("time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)). // Will be skipped, too long.
+			LabelTextAppender("failing", failingTextAppender{}).                    // Will be skipped.
+			LabelTextAppender("nil", nil).
+			LabelAppend("id", appenderValue{"abc"}).
+			LabelAppend("empty", appenderValue{""}). // Will be skipped.
+			LabelStringer("stringer", appenderValue{"def"}).
+			String()
+		require.Equal(t, `test_appender{ip="192.168.0.1",id="abc",stringer="def"}`, metric)
+	})
+
+	require.Len(t, logLines, 3)
+}
+
+func TestBuilderLabelAppenderAllocs(t *testing.T) {
+	// Passing a pointer avoids the allocation caused by converting netip.Addr to an interface.
+	addr := netip.MustParseAddr("2001:db8::1")
+	id := &appenderValue{"abc"}
+	builder := AcquireBuilder()
+	defer ReleaseBuilder(builder)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		builder.Reset()
+		builder.Metric("test_appender").LabelTextAppender("ip", &addr).LabelAppend("id", id)
+	})
+	require.Zero(t, allocs)
+}
+
+func TestBuilderFloatFormat(t *testing.T) {
+	metric := Metric("test_float").
+		LabelFloat64("nan", math.NaN()).
+		LabelFloat64("pos_inf", math.Inf(1)).
+		LabelFloat32("neg_inf", float32(math.Inf(-1))).
+		LabelFloat64("default", 1234.5).
+		LabelFloat64Format("shortest", 1e300, 'g', -1).
+		LabelFloat64Format("fixed", math.Pi, 'f', 2).
+		LabelFloat32Format("exponent", 1234.5, 'e', 3).
+		String()
+	require.Equal(t, `test_float{nan="NaN",pos_inf="+Inf",neg_inf="-Inf",default="1234.5",shortest="1e+300",fixed="3.14",exponent="1.234e+03"}`, metric)
+
+	metric = Metric("test_float", WithFloatFormat('g', 4)).
+		LabelFloat64("large", 1e300).
+		LabelFloat64("pi", math.Pi).
+		LabelFloat64Format("per_call", math.Pi, 'f', 1).
+		String()
+	require.Equal(t, `test_float{large="1e+300",pi="3.142",per_call="3.1"}`, metric)
+}
+
 func TestBuilderReset(t *testing.T) {
-	options := []BuilderOption{WithLabelNameMaxLen(64), WithLabelValueMaxLen(256)}
+	options := []BuilderOption{WithLabelNameMaxLen(64), WithLabelValueMaxLen(256), WithFloatFormat('g', 3)}
 	builder := Metric("test_reset", options...).LabelString("test", "something")
 
 	require.NotNil(t, builder.pool)
@@ -365,14 +438,34 @@ func TestBuilderReset(t *testing.T) {
 	require.True(t, builder.hasFlag(flagHasLabel))
 	require.Equal(t, 64, builder.labelNameMaxLen)
 	require.Equal(t, 256, builder.labelValueMaxLen)
+	require.Equal(t, byte('g'), builder.floatFormat)
+	require.Equal(t, 3, builder.floatPrecision)
 
 	builder.Reset()
 
-	require.Nil(t, builder.pool)
+	require.NotNil(t, builder.pool) // The builder stays attached to its pool until it is released.
 	require.False(t, builder.hasFlag(flagHasMetricName))
 	require.False(t, builder.hasFlag(flagHasLabel))
 	require.Equal(t, 0, builder.labelNameMaxLen)
 	require.Equal(t, 0, builder.labelValueMaxLen)
+	require.Zero(t, builder.floatFormat)
+	require.Zero(t, builder.floatPrecision)
+}
+
+func TestBuilderPoolAcquireReset(t *testing.T) {
+	pool := NewBuilderPool(WithLabelRedaction(RedactEmails))
+	pool.SetPolicy(&Policy{Metrics: map[string]MetricPolicy{"disabled_total": {Disabled: true}}})
+
+	b := pool.Acquire()
+	defer pool.Release(b)
+	captureLogOutput(func() {
+		for range 2 {
+			require.Equal(t, `m{e="[redacted]"}`, b.Metric("m").LabelString("e", "a@b.com").String())
+			b.Reset()
+			require.Empty(t, b.Metric("disabled_total").String())
+			b.Reset()
+		}
+	})
 }
 
 func BenchmarkBuilderTestCasesParallel(b *testing.B) {
@@ -0,0 +1,61 @@
+package vimebu
+
+import (
+	"context"
+	"slices"
+)
+
+type contextLabelsKey struct{}
+
+// ContextWithLabels returns a copy of ctx carrying the provided labels, in addition to
+// the ones already attached to ctx. If a label name is already attached to ctx, its value
+// is replaced.
+//
+// This allows attaching labels (e.g. tenant, route, caller) at the request boundary, to be
+// added by code deeper in the call stack using [MetricCtx] or [Builder.LabelsFromContext].
+func ContextWithLabels(ctx context.Context, labels Labels) context.Context {
+	if len(labels) == 0 {
+		return ctx
+	}
+	merged := slices.Clone(LabelsFromContext(ctx))
+	for _, label := range labels {
+		i := slices.IndexFunc(merged, func(l Label) bool {
+			return l.Name == label.Name
+		})
+		if i >= 0 {
+			merged[i].Value = label.Value
+		} else {
+			merged = append(merged, label)
+		}
+	}
+	return context.WithValue(ctx, contextLabelsKey{}, merged)
+}
+
+// LabelsFromContext returns the labels attached to ctx using [ContextWithLabels].
+//
+// The returned labels mustn't be modified.
+func LabelsFromContext(ctx context.Context) Labels {
+	labels, _ := ctx.Value(contextLabelsKey{}).(Labels)
+	return labels
+}
+
+// LabelsFromContext adds the labels attached to ctx using [ContextWithLabels] to the [Builder].
+//
+// NoOp if no labels are attached to ctx.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelsFromContext(ctx context.Context) *Builder {
+	return b.Labels(LabelsFromContext(ctx))
+}
+
+// MetricCtx acquires and returns a zeroed-out [Builder] instance from the
+// default builder pool, sets the metric's name, and adds the labels attached to ctx.
+func MetricCtx(ctx context.Context, name string, options ...BuilderOption) *Builder {
+	return DefaultBuilderPool().MetricCtx(ctx, name, options...)
+}
+
+// MetricCtx acquires and returns a zeroed-out [Builder] instance from the
+// specified pool, sets the metric's name, and adds the labels attached to ctx.
+func (p *BuilderPool) MetricCtx(ctx context.Context, name string, options ...BuilderOption) *Builder {
+	return p.Metric(name, options...).LabelsFromContext(ctx)
+}
@@ -0,0 +1,28 @@
+package vimebu
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithLabels(t *testing.T) {
+	ctx := t.Context()
+	require.Same(t, ctx, ContextWithLabels(ctx, nil))
+	require.Nil(t, LabelsFromContext(ctx))
+
+	parent := ContextWithLabels(ctx, Labels{{"tenant", "acme"}, {"route", "/users"}})
+	child := ContextWithLabels(parent, Labels{{"route", "/users/{id}"}, {"caller", "billing"}})
+
+	require.Equal(t, Labels{{"tenant", "acme"}, {"route", "/users"}}, LabelsFromContext(parent))
+	require.Equal(t, Labels{{"tenant", "acme"}, {"route", "/users/{id}"}, {"caller", "billing"}}, LabelsFromContext(child))
+}
+
+func TestMetricCtx(t *testing.T) {
+	ctx := ContextWithLabels(context.Background(), Labels{{"tenant", "acme"}})
+
+	require.Equal(t, `cache_hits_total{tenant="acme",cache="users"}`, MetricCtx(ctx, "cache_hits_total").LabelString("cache", "users").String())
+	require.Equal(t, `cache_hits_total{cache="users",tenant="acme"}`, Metric("cache_hits_total").LabelString("cache", "users").LabelsFromContext(ctx).String())
+	require.Equal(t, `cache_hits_total`, MetricCtx(context.Background(), "cache_hits_total").String())
+}
@@ -0,0 +1,78 @@
+package vimebu
+
+const defaultEnumFallback string = "other"
+
+// EnumOption represents a modifier function that will apply a specific
+// configuration to an [Enum] instance.
+type EnumOption func(*Enum)
+
+// WithEnumFallback sets the value used in place of values not declared in the [Enum].
+//
+// Defaults to "other".
+func WithEnumFallback(fallback string) EnumOption {
+	return func(e *Enum) {
+		e.fallback = fallback
+	}
+}
+
+// WithEnumReporter sets a function called with each value not declared in the [Enum],
+// before it is replaced by the fallback value.
+//
+// It must be safe for concurrent calls.
+func WithEnumReporter(report func(value string)) EnumOption {
+	return func(e *Enum) {
+		e.report = report
+	}
+}
+
+// Enum is a declared set of label values, used to bound the cardinality of labels whose values
+// come from untrusted or open-ended sources, such as HTTP methods or client supplied fields.
+//
+// [Enum] instances are immutable, and safe to use from concurrently running goroutines.
+type Enum struct {
+	values   map[string]struct{}
+	fallback string
+	report   func(value string)
+}
+
+// NewEnum creates a new [Enum] instance, only allowing the provided values.
+func NewEnum(allowed []string, options ...EnumOption) *Enum {
+	e := &Enum{
+		values:   make(map[string]struct{}, len(allowed)),
+		fallback: defaultEnumFallback,
+	}
+	for _, value := range allowed {
+		e.values[value] = struct{}{}
+	}
+	for _, applyOption := range options {
+		applyOption(e)
+	}
+	return e
+}
+
+// Contains reports whether value is declared in the [Enum].
+func (e *Enum) Contains(value string) bool {
+	_, ok := e.values[value]
+	return ok
+}
+
+// Value returns value if it is declared in the [Enum], or the fallback value otherwise.
+func (e *Enum) Value(value string) string {
+	if e.Contains(value) {
+		return value
+	}
+	if e.report != nil {
+		e.report(value)
+	}
+	return e.fallback
+}
+
+// LabelEnum adds a label with a value of type string to the [Builder], replacing it with the
+// fallback value of enum if it isn't declared in it (see [Enum.Value]).
+//
+// NoOp if the label name is empty, or if the resulting value is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelEnum(name, value string, enum *Enum) *Builder {
+	return b.LabelString(name, enum.Value(value))
+}
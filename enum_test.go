@@ -0,0 +1,27 @@
+package vimebu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnum(t *testing.T) {
+	var unexpected []string
+	enum := NewEnum([]string{"card", "transfer"}, WithEnumFallback("unknown"), WithEnumReporter(func(value string) {
+		unexpected = append(unexpected, value)
+	}))
+
+	require.True(t, enum.Contains("card"))
+	require.False(t, enum.Contains("cash"))
+	require.Equal(t, "transfer", enum.Value("transfer"))
+	require.Equal(t, "unknown", enum.Value("cash"))
+	require.Equal(t, []string{"cash"}, unexpected)
+}
+
+func TestBuilderLabelEnum(t *testing.T) {
+	enum := NewEnum([]string{"GET", "POST"})
+
+	require.Equal(t, `requests_total{method="GET"}`, Metric("requests_total").LabelEnum("method", "GET", enum).String())
+	require.Equal(t, `requests_total{method="other"}`, Metric("requests_total").LabelEnum("method", "BREW", enum).String())
+}
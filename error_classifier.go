@@ -0,0 +1,166 @@
+package vimebu
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Error classes returned by the [DefaultErrorClassifier].
+const (
+	ErrorClassTimeout           string = "timeout"
+	ErrorClassCanceled          string = "canceled"
+	ErrorClassNotFound          string = "not_found"
+	ErrorClassPermissionDenied  string = "permission_denied"
+	ErrorClassEOF               string = "eof"
+	ErrorClassDNS               string = "dns"
+	ErrorClassConnectionRefused string = "connection_refused"
+	ErrorClassConnectionReset   string = "connection_reset"
+	ErrorClassTLS               string = "tls"
+	ErrorClassNetwork           string = "network"
+	ErrorClassOther             string = "other"
+)
+
+// DefaultErrorClassifier is the [ErrorClassifier] used by the [Builder] methods classifying errors,
+// unless another one is set using the [WithErrorClassifier] option.
+//
+// It classifies context, file system, network and TLS errors using the ErrorClass* constants.
+// Custom rules can be registered on it, they take precedence over the builtin ones.
+var DefaultErrorClassifier = newDefaultErrorClassifier()
+
+// ErrorClassifier maps errors to a bounded set of stable classes, to be used as label values
+// instead of error messages, which may embed IDs, addresses, timestamps etc.
+//
+// Rules are evaluated from the most recently registered one to the oldest one, the first
+// matching rule gives the class of the error. If no rule matches, the class is the name
+// of the error type (e.g. "fs.PathError"), looking through the errors created by [fmt.Errorf].
+// If it can't be determined, the class is [ErrorClassOther].
+//
+// The zero value is ready to use, and has no rules.
+//
+// [ErrorClassifier] instances are safe to use from concurrently running goroutines.
+type ErrorClassifier struct {
+	mu    sync.RWMutex
+	rules []func(error) (string, bool)
+}
+
+// NewErrorClassifier creates a new [ErrorClassifier] instance, with no rules.
+func NewErrorClassifier() *ErrorClassifier {
+	return &ErrorClassifier{}
+}
+
+// RegisterFunc registers a rule classifying the errors for which classify returns true.
+func (c *ErrorClassifier) RegisterFunc(classify func(err error) (class string, ok bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = append(c.rules, classify)
+}
+
+// RegisterIs registers a rule classifying the errors matching target using [errors.Is].
+func (c *ErrorClassifier) RegisterIs(target error, class string) {
+	c.RegisterFunc(func(err error) (string, bool) {
+		return class, errors.Is(err, target)
+	})
+}
+
+// RegisterErrorAs registers a rule on c classifying the errors matching the type E using [errors.As].
+func RegisterErrorAs[E error](c *ErrorClassifier, class string) {
+	c.RegisterFunc(func(err error) (string, bool) {
+		var target E
+		return class, errors.As(err, &target)
+	})
+}
+
+// Classify returns the class of err, or an empty string if err is nil.
+func (c *ErrorClassifier) Classify(err error) string {
+	if err == nil {
+		return ""
+	}
+	c.mu.RLock()
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if class, ok := c.rules[i](err); ok {
+			c.mu.RUnlock()
+			return class
+		}
+	}
+	c.mu.RUnlock()
+	return errorTypeName(err)
+}
+
+// opaqueErrorTypes holds the types of the errors created by the standard library, whose names
+// don't carry any meaning.
+var opaqueErrorTypes = map[reflect.Type]struct{}{
+	reflect.TypeOf(errors.New("")):                                      {},
+	reflect.TypeOf(errors.Join(errors.New(""))):                         {},
+	reflect.TypeOf(fmt.Errorf("%w", errors.New(""))):                    {},
+	reflect.TypeOf(fmt.Errorf("%w %w", errors.New(""), errors.New(""))): {},
+}
+
+// errorTypeName returns the name of the type of err, unwrapping the errors created by [fmt.Errorf].
+func errorTypeName(err error) string {
+	for err != nil {
+		typ := reflect.TypeOf(err)
+		if _, ok := opaqueErrorTypes[typ]; !ok {
+			return strings.TrimLeft(typ.String(), "*")
+		}
+		err = errors.Unwrap(err)
+	}
+	return ErrorClassOther
+}
+
+func newDefaultErrorClassifier() *ErrorClassifier {
+	c := NewErrorClassifier()
+	c.RegisterFunc(func(err error) (string, bool) {
+		var netErr net.Error
+		return ErrorClassTimeout, errors.As(err, &netErr) && netErr.Timeout()
+	})
+	RegisterErrorAs[*net.OpError](c, ErrorClassNetwork)
+	c.RegisterIs(io.EOF, ErrorClassEOF)
+	c.RegisterIs(io.ErrUnexpectedEOF, ErrorClassEOF)
+	c.RegisterIs(fs.ErrNotExist, ErrorClassNotFound)
+	c.RegisterIs(sql.ErrNoRows, ErrorClassNotFound)
+	c.RegisterIs(fs.ErrPermission, ErrorClassPermissionDenied)
+	c.RegisterIs(syscall.ECONNRESET, ErrorClassConnectionReset)
+	c.RegisterIs(syscall.ECONNREFUSED, ErrorClassConnectionRefused)
+	c.RegisterIs(syscall.ETIMEDOUT, ErrorClassTimeout)
+	c.RegisterFunc(func(err error) (string, bool) {
+		return ErrorClassTLS, isTLSError(err)
+	})
+	RegisterErrorAs[*net.DNSError](c, ErrorClassDNS)
+	c.RegisterIs(os.ErrDeadlineExceeded, ErrorClassTimeout)
+	c.RegisterIs(context.DeadlineExceeded, ErrorClassTimeout)
+	c.RegisterIs(context.Canceled, ErrorClassCanceled)
+	return c
+}
+
+func isTLSError(err error) bool {
+	var (
+		recordHeaderErr tls.RecordHeaderError
+		alertErr        tls.AlertError
+		verificationErr *tls.CertificateVerificationError
+		echRejectionErr *tls.ECHRejectionError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		certInvalidErr  x509.CertificateInvalidError
+		systemRootsErr  x509.SystemRootsError
+	)
+	return errors.As(err, &recordHeaderErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &verificationErr) ||
+		errors.As(err, &echRejectionErr) ||
+		errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &certInvalidErr) ||
+		errors.As(err, &systemRootsErr)
+}
@@ -0,0 +1,66 @@
+package vimebu
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type customError struct{}
+
+func (customError) Error() string {
+	return "custom"
+}
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{context.Canceled, ErrorClassCanceled},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{&url.Error{Op: "Get", URL: "http://foo", Err: &net.DNSError{Err: "no such host", Name: "foo"}}, ErrorClassDNS},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ErrorClassConnectionRefused},
+		{&net.OpError{Op: "read", Err: errors.New("boom")}, ErrorClassNetwork},
+		{&fs.PathError{Op: "open", Path: "/foo", Err: fs.ErrNotExist}, ErrorClassNotFound},
+		{fmt.Errorf("user 1234: %w", sql.ErrNoRows), ErrorClassNotFound},
+		{&fs.PathError{Op: "open", Path: "/foo", Err: errors.New("boom")}, "fs.PathError"},
+		{fmt.Errorf("wrapped: %w", customError{}), "vimebu.customError"},
+		{errors.New("user 1234 is broken"), ErrorClassOther},
+		{errors.Join(errors.New("a"), errors.New("b")), ErrorClassOther},
+	} {
+		require.Equal(t, tc.expected, DefaultErrorClassifier.Classify(tc.err), tc.err.Error())
+	}
+	require.Equal(t, "", DefaultErrorClassifier.Classify(nil))
+}
+
+func TestErrorClassifierPrecedence(t *testing.T) {
+	var c ErrorClassifier
+	c.RegisterIs(context.DeadlineExceeded, "timeout")
+	RegisterErrorAs[customError](&c, "custom")
+	c.RegisterFunc(func(err error) (string, bool) {
+		return "upstream_timeout", errors.Is(err, context.DeadlineExceeded) && errors.As(err, new(customError))
+	})
+
+	require.Equal(t, "timeout", c.Classify(context.DeadlineExceeded))
+	require.Equal(t, "custom", c.Classify(customError{}))
+	require.Equal(t, "upstream_timeout", c.Classify(errors.Join(customError{}, context.DeadlineExceeded)))
+}
+
+func TestBuilderLabelErrorClass(t *testing.T) {
+	classifier := NewErrorClassifier()
+	classifier.RegisterIs(sql.ErrNoRows, "no_rows")
+
+	require.Equal(t, `test_errors{error="timeout"}`, Metric("test_errors").LabelErrorClass(fmt.Errorf("query 1234: %w", context.DeadlineExceeded)).String())
+	require.Equal(t, `test_errors{reason="no_rows"}`, Metric("test_errors", WithErrorClassifier(classifier)).LabelNamedErrorClass("reason", sql.ErrNoRows).String())
+	require.Equal(t, `test_errors`, Metric("test_errors").LabelErrorClass(nil).String())
+}
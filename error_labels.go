@@ -0,0 +1,92 @@
+package vimebu
+
+import "errors"
+
+// LabeledError is an error annotated with metric labels, created using [ErrorWithLabels].
+type LabeledError struct {
+	err    error
+	labels Labels
+}
+
+// ErrorWithLabels annotates err with metric labels, allowing lower layers to describe
+// a failure (e.g. reason, retryable) without knowing which metrics will be emitted.
+//
+// The labels can be retrieved using [ErrorLabels] or [Builder.LabelsFromError].
+//
+// Returns nil if err is nil.
+func ErrorWithLabels(err error, labels Labels) error {
+	if err == nil {
+		return nil
+	}
+	return &LabeledError{err: err, labels: labels}
+}
+
+// Error returns the message of the wrapped error.
+func (e *LabeledError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *LabeledError) Unwrap() error {
+	return e.err
+}
+
+// Labels returns the labels attached to this error only.
+func (e *LabeledError) Labels() Labels {
+	return e.labels
+}
+
+// ErrorLabels walks the chain of err (see [errors.Unwrap]) and returns all the labels attached to it
+// using [ErrorWithLabels].
+//
+// Labels attached by the outer layers come first. If a label name is attached multiple times,
+// only the outermost value is kept.
+func ErrorLabels(err error) Labels {
+	var labels Labels
+	walkErrorChain(err, func(err error) {
+		le, ok := err.(*LabeledError)
+		if !ok {
+			return
+		}
+		for _, label := range le.labels {
+			if _, found := labels.Get(label.Name); !found {
+				labels = append(labels, label)
+			}
+		}
+	})
+	return labels
+}
+
+// walkErrorChain calls fn for err and each of the errors it wraps, depth first.
+func walkErrorChain(err error, fn func(error)) {
+	for err != nil {
+		fn(err)
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				walkErrorChain(err, fn)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// LabelsFromError adds all the labels attached to err using [ErrorWithLabels] to the [Builder],
+// see [ErrorLabels].
+//
+// NoOp if err is nil, or if no labels are attached to it.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelsFromError(err error) *Builder {
+	if err == nil {
+		return b
+	}
+	if !errors.As(err, new(*LabeledError)) { // Fast path, avoids allocating when no labels are attached.
+		return b
+	}
+	return b.Labels(ErrorLabels(err))
+}
@@ -0,0 +1,34 @@
+package vimebu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorWithLabels(t *testing.T) {
+	require.NoError(t, ErrorWithLabels(nil, Labels{{"reason", "none"}}))
+
+	base := errors.New("connection reset")
+	inner := ErrorWithLabels(base, Labels{{"reason", "network"}, {"retryable", "true"}})
+	outer := ErrorWithLabels(fmt.Errorf("fetching user: %w", inner), Labels{{"reason", "upstream"}, {"service", "users"}})
+
+	require.ErrorIs(t, outer, base)
+	require.Equal(t, "fetching user: connection reset", outer.Error())
+	require.Equal(t, Labels{{"reason", "upstream"}, {"service", "users"}, {"retryable", "true"}}, ErrorLabels(outer))
+
+	joined := errors.Join(inner, ErrorWithLabels(base, Labels{{"shard", "2"}}))
+	require.Equal(t, Labels{{"reason", "network"}, {"retryable", "true"}, {"shard", "2"}}, ErrorLabels(joined))
+
+	require.Nil(t, ErrorLabels(base))
+}
+
+func TestBuilderLabelsFromError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", ErrorWithLabels(errors.New("boom"), Labels{{"reason", `"bad" input`}, {"retryable", "false"}}))
+
+	require.Equal(t, `errors_total{op="get",reason="\"bad\" input",retryable="false"}`, Metric("errors_total").LabelString("op", "get").LabelsFromError(err).String())
+	require.Equal(t, `errors_total`, Metric("errors_total").LabelsFromError(errors.New("boom")).String())
+	require.Equal(t, `errors_total`, Metric("errors_total").LabelsFromError(nil).String())
+}
@@ -0,0 +1,70 @@
+package vimebu
+
+import "strconv"
+
+const (
+	fnvOffset64 uint64 = 14695981039346656037
+	fnvPrime64  uint64 = 1099511628211
+
+	hexDigits string = "0123456789abcdef"
+	hashLen   int    = 16
+)
+
+// hashLabelValue computes the 64-bit FNV-1a hash of value, without allocating.
+func hashLabelValue[T string | []byte](value T) uint64 {
+	h := fnvOffset64
+	for i := 0; i < len(value); i++ {
+		h ^= uint64(value[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// appendLabelValueHash appends h to dst, as 16 zero-padded hexadecimal digits.
+func appendLabelValueHash(dst []byte, h uint64) []byte {
+	for shift := 60; shift >= 0; shift -= 4 {
+		dst = append(dst, hexDigits[(h>>shift)&0xf])
+	}
+	return dst
+}
+
+// LabelHashed adds a label with a bounded and stable identifier derived from value to the [Builder],
+// instead of the raw value.
+//
+// If buckets is zero, the identifier is the 64-bit FNV-1a hash of value, formatted as 16 hexadecimal digits.
+// If the [WithLabelValueHashOverflow] option is set with a max len lower than 16, the hash is truncated to its
+// leading hexadecimal digits, instead of being hashed again.
+// Otherwise, it is the hash modulo buckets, formatted as a decimal number, bounding the label cardinality
+// to buckets values.
+//
+// If the [Builder] was acquired from a [BuilderPool], the normalizers and redaction rules of the pool
+// (see [WithLabelNormalizers] and [WithLabelRedaction]) are applied to value before hashing it.
+//
+// NoOp if the label name or value are empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelHashed(name, value string, buckets uint64) *Builder {
+	if !b.hasFlag(flagHasMetricName) {
+		panic("vimebu: can't add a label to a Builder with no metric name")
+	}
+	if !b.isValidLabelName(name) {
+		return b
+	}
+	value, ok := b.rewriteLabelValue(name, value)
+	if !ok {
+		return b
+	}
+	if len(value) == 0 { // Let the string path write the placeholder, or report the empty value.
+		return b.labelValue(name, value, true)
+	}
+	return b.appendLabelValue(name, func(dst []byte) ([]byte, error) {
+		h := hashLabelValue(value)
+		if buckets > 0 {
+			return strconv.AppendUint(dst, h%buckets, base10), nil
+		}
+		if b.overflowsLabelValue(hashLen) { // Truncated rather than hashed again.
+			return b.appendOverflowHash(dst, h), nil
+		}
+		return appendLabelValueHash(dst, h), nil
+	})
+}
@@ -0,0 +1,95 @@
+package vimebu
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashLabelValue(t *testing.T) {
+	for _, value := range []string{"", "a", "user-1234", "some much longer value with spaces"} {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(value))
+		require.Equal(t, h.Sum64(), hashLabelValue(value))
+		require.Equal(t, h.Sum64(), hashLabelValue([]byte(value)))
+	}
+	require.Equal(t, "000000000000002a", string(appendLabelValueHash(nil, 42)))
+}
+
+func TestBuilderLabelHashed(t *testing.T) {
+	metric := Metric("debug_total").
+		LabelHashed("user", "user-1234", 0).
+		LabelHashed("shard", "user-1234", 16).
+		String()
+	require.Equal(t, `debug_total{user="5510577624a45805",shard="5"}`, metric)
+
+	// Stable across builders.
+	require.Equal(t, metric, Metric("debug_total").LabelHashed("user", "user-1234", 0).LabelHashed("shard", "user-1234", 16).String())
+
+	logLines := captureLogOutput(func() {
+		require.Equal(t, `debug_total`, Metric("debug_total").LabelHashed("user", "", 0).String())
+	})
+	require.Len(t, logLines, 1)
+}
+
+func TestBuilderPoolLabelHashed(t *testing.T) {
+	pool := NewBuilderPool(
+		WithLabelNormalizers("user", NormalizeLower, NormalizePathTemplate),
+		WithLabelRedaction(RedactEmails),
+	)
+	hashed := func(value string) string {
+		return `debug_total{user="` + string(appendLabelValueHash(nil, hashLabelValue(value))) + `"}`
+	}
+
+	// The value is normalized before being hashed, and the hash is left as is.
+	require.Equal(t, hashed("alice"), pool.Metric("debug_total").LabelHashed("user", "Alice", 0).String())
+	require.Equal(t, hashed("alice"), pool.Metric("debug_total").LabelHashed("user", "alice", 0).String())
+	require.Equal(t, hashed("bob"), pool.Metric("debug_total").LabelHashed("user", "BOB", 0).String())
+
+	// The value is redacted before being hashed.
+	captureLogOutput(func() {
+		require.Equal(t, hashed("[redacted]"), pool.Metric("debug_total").LabelHashed("user", "alice@example.com", 0).String())
+	})
+}
+
+func TestBuilderOptionsWithLabelValueHashOverflow(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("test_options", WithLabelValueMaxLen(16), WithLabelValueHashOverflow()).
+			LabelString("short", "short").
+			LabelString("long", "user-1234@example.com").
+			LabelAppend("appended", appenderValue{"user-1234@example.com"}).
+			String()
+		require.Equal(t, `test_options{short="short",long="4da5f5ec4e1607c8",appended="4da5f5ec4e1607c8"}`, metric)
+	})
+	require.Empty(t, logLines)
+
+	// The hash is truncated when the max len is lower than its 16 hexadecimal digits.
+	logLines = captureLogOutput(func() {
+		metric := Metric("test_options", WithLabelValueMaxLen(5), WithLabelValueHashOverflow()).
+			LabelString("short", "short").
+			LabelString("long", "user-1234").
+			LabelAppend("appended", appenderValue{"user-1234"}).
+			String()
+		require.Equal(t, `test_options{short="short",long="55105",appended="55105"}`, metric)
+	})
+	require.Empty(t, logLines)
+}
+
+func TestBuilderLabelHashedWithLabelValueHashOverflow(t *testing.T) {
+	hash := string(appendLabelValueHash(nil, hashLabelValue("zz")))
+	require.Equal(t, "08f7", hash[:4])
+
+	// The hash is truncated to the max len, instead of being hashed again.
+	metric := Metric("debug_total", WithLabelValueMaxLen(4), WithLabelValueHashOverflow()).
+		LabelHashed("h", "zz", 0).
+		LabelHashed("long", "user-1234@example.com", 0).
+		String()
+	require.Equal(t, `debug_total{h="08f7",long="4da5"}`, metric)
+
+	// Without the option, the hash exceeding the max len is skipped.
+	logLines := captureLogOutput(func() {
+		require.Equal(t, "debug_total", Metric("debug_total", WithLabelValueMaxLen(4)).LabelHashed("h", "zz", 0).String())
+	})
+	require.Len(t, logLines, 1)
+}
@@ -0,0 +1,145 @@
+package vimebu
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+const (
+	defaultHTTPClientPrefix string = "http_client"
+
+	httpHostLabelName   string = "host"
+	httpReasonLabelName string = "reason"
+
+	httpErrorStatus string = "error"
+)
+
+// Reasons of the failed requests recorded by the [http.RoundTripper] returned by [InstrumentRoundTripper]
+// with the [DefaultErrorClassifier]. They are aliases of the matching ErrorClass* values.
+const (
+	HTTPClientErrorTimeout           string = ErrorClassTimeout
+	HTTPClientErrorCanceled          string = ErrorClassCanceled
+	HTTPClientErrorDNS               string = ErrorClassDNS
+	HTTPClientErrorConnectionRefused string = ErrorClassConnectionRefused
+	HTTPClientErrorTLS               string = ErrorClassTLS
+	HTTPClientErrorOther             string = ErrorClassOther
+)
+
+// HTTPClientOption represents a modifier function that will apply a specific
+// configuration to the [http.RoundTripper] returned by [InstrumentRoundTripper].
+type HTTPClientOption func(*httpClientConfig)
+
+type httpClientConfig struct {
+	set        *metrics.Set
+	prefix     string
+	classifier *ErrorClassifier
+}
+
+// WithHTTPClientSet sets the [metrics.Set] in which the metrics will be registered.
+//
+// Defaults to the default [metrics.Set].
+func WithHTTPClientSet(set *metrics.Set) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.set = set
+	}
+}
+
+// WithHTTPClientPrefix sets the prefix of the recorded metric names.
+//
+// Defaults to "http_client".
+func WithHTTPClientPrefix(prefix string) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.prefix = prefix
+	}
+}
+
+// WithHTTPClientErrorClassifier sets the [ErrorClassifier] used to compute the reason label of failed requests.
+//
+// Defaults to the [DefaultErrorClassifier].
+func WithHTTPClientErrorClassifier(classifier *ErrorClassifier) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.classifier = classifier
+	}
+}
+
+// InstrumentRoundTripper returns a [http.RoundTripper] recording the following metrics for each request sent using next :
+//
+//   - <prefix>_requests_total{host="...",status="..."} : counter of sent requests
+//   - <prefix>_request_duration_seconds{host="...",status="..."} : histogram of the requests duration
+//   - <prefix>_errors_total{host="...",reason="..."} : counter of requests which failed without receiving a response
+//
+// The status label holds the status class of the response (e.g. "2xx"), or "error" if the request failed.
+// The reason label holds the class of the error (e.g. "timeout", "dns", "connection_refused", "tls"),
+// instead of its message, see [WithHTTPClientErrorClassifier].
+//
+// If next is nil, [http.DefaultTransport] is used.
+//
+// Panics if the metric prefix is empty.
+func InstrumentRoundTripper(next http.RoundTripper, options ...HTTPClientOption) http.RoundTripper {
+	cfg := httpClientConfig{
+		set:        metrics.GetDefaultSet(),
+		prefix:     defaultHTTPClientPrefix,
+		classifier: DefaultErrorClassifier,
+	}
+	for _, applyOption := range options {
+		applyOption(&cfg)
+	}
+	if len(cfg.prefix) == 0 {
+		panic("vimebu: InstrumentRoundTripper has been passed an empty metric prefix")
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &httpClientRoundTripper{
+		next:         next,
+		set:          cfg.set,
+		classifier:   cfg.classifier,
+		requestsName: cfg.prefix + "_requests_total",
+		durationName: cfg.prefix + "_request_duration_seconds",
+		errorsName:   cfg.prefix + "_errors_total",
+	}
+}
+
+type httpClientRoundTripper struct {
+	next       http.RoundTripper
+	set        *metrics.Set
+	classifier *ErrorClassifier
+
+	requestsName string
+	durationName string
+	errorsName   string
+}
+
+func (rt *httpClientRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+	duration := time.Since(start).Seconds()
+
+	host := req.URL.Host
+	if len(host) == 0 {
+		host = req.Host
+	}
+	status := httpErrorStatus
+	if err == nil {
+		status = httpStatusClass(resp.StatusCode)
+	} else {
+		Metric(rt.errorsName).
+			LabelStringQuote(httpHostLabelName, host).
+			LabelStringQuote(httpReasonLabelName, rt.classifier.Classify(err)).
+			GetOrCreateCounterInSet(rt.set).
+			Inc()
+	}
+	Metric(rt.requestsName).
+		LabelStringQuote(httpHostLabelName, host).
+		LabelString(httpStatusLabelName, status).
+		GetOrCreateCounterInSet(rt.set).
+		Inc()
+	Metric(rt.durationName).
+		LabelStringQuote(httpHostLabelName, host).
+		LabelString(httpStatusLabelName, status).
+		GetOrCreateHistogramInSet(rt.set).
+		Update(duration)
+	return resp, err
+}
@@ -0,0 +1,91 @@
+package vimebu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	set := metrics.NewSet()
+	client := &http.Client{Transport: InstrumentRoundTripper(nil, WithHTTPClientSet(set))}
+	host := server.Listener.Addr().String()
+
+	for _, path := range []string{"/", "/", "/missing"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	out := writeSet(set)
+	require.Contains(t, out, fmt.Sprintf(`http_client_requests_total{host=%q,status="2xx"} 2`, host))
+	require.Contains(t, out, fmt.Sprintf(`http_client_requests_total{host=%q,status="4xx"} 1`, host))
+	require.Contains(t, out, fmt.Sprintf(`http_client_request_duration_seconds_count{host=%q,status="2xx"} 2`, host))
+	require.NotContains(t, out, "http_client_errors_total")
+}
+
+func TestInstrumentRoundTripperErrors(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer secure.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	set := metrics.NewSet()
+	client := &http.Client{Transport: InstrumentRoundTripper(&http.Transport{}, WithHTTPClientSet(set), WithHTTPClientPrefix("out"))}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, slow.URL, nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	require.Error(t, err)
+
+	_, err = client.Get(secure.URL)
+	require.Error(t, err)
+
+	_, err = client.Get(closed.URL)
+	require.Error(t, err)
+
+	out := writeSet(set)
+	require.Contains(t, out, fmt.Sprintf(`out_errors_total{host=%q,reason="timeout"} 1`, slow.Listener.Addr()))
+	require.Contains(t, out, fmt.Sprintf(`out_errors_total{host=%q,reason="tls"} 1`, secure.Listener.Addr()))
+	require.Contains(t, out, fmt.Sprintf(`out_errors_total{host=%q,reason="connection_refused"} 1`, closed.Listener.Addr()))
+	require.Contains(t, out, fmt.Sprintf(`out_requests_total{host=%q,status="error"} 1`, slow.Listener.Addr()))
+}
+
+func TestHTTPClientErrorReason(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{context.Canceled, HTTPClientErrorCanceled},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), HTTPClientErrorTimeout},
+		{&url.Error{Op: "Get", URL: "http://foo", Err: &net.DNSError{Err: "no such host", Name: "foo"}}, HTTPClientErrorDNS},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, HTTPClientErrorConnectionRefused},
+		{errors.New("boom"), HTTPClientErrorOther},
+	} {
+		require.Equal(t, tc.expected, DefaultErrorClassifier.Classify(tc.err), tc.err.Error())
+	}
+}
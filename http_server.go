@@ -0,0 +1,216 @@
+package vimebu
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+const (
+	defaultHTTPServerPrefix string = "http_server"
+
+	httpMethodLabelName string = "method"
+	httpRouteLabelName  string = "route"
+	httpStatusLabelName string = "status"
+
+	httpOtherMethod    string = "other"
+	httpUnmatchedRoute string = "unmatched"
+)
+
+// HTTPServerOption represents a modifier function that will apply a specific
+// configuration to the middleware returned by [InstrumentHTTPHandler].
+type HTTPServerOption func(*httpServerConfig)
+
+type httpServerConfig struct {
+	set         *metrics.Set
+	prefix      string
+	statusClass bool
+}
+
+// WithHTTPServerSet sets the [metrics.Set] in which the metrics will be registered.
+//
+// Defaults to the default [metrics.Set].
+func WithHTTPServerSet(set *metrics.Set) HTTPServerOption {
+	return func(c *httpServerConfig) {
+		c.set = set
+	}
+}
+
+// WithHTTPServerPrefix sets the prefix of the recorded metric names.
+//
+// Defaults to "http_server".
+func WithHTTPServerPrefix(prefix string) HTTPServerOption {
+	return func(c *httpServerConfig) {
+		c.prefix = prefix
+	}
+}
+
+// WithHTTPServerStatusClass records the status class of the responses (e.g. "2xx")
+// instead of their exact status code.
+func WithHTTPServerStatusClass() HTTPServerOption {
+	return func(c *httpServerConfig) {
+		c.statusClass = true
+	}
+}
+
+// InstrumentHTTPHandler returns a middleware recording the following metrics for each request handled by next :
+//
+//   - <prefix>_requests_total{method="...",route="...",status="..."} : counter of handled requests
+//   - <prefix>_request_duration_seconds{method="...",route="...",status="..."} : histogram of the requests duration
+//   - <prefix>_response_size_bytes{method="...",route="...",status="..."} : histogram of the response body sizes
+//   - <prefix>_requests_in_flight{method="..."} : gauge of the requests currently being handled
+//
+// The route label is the pattern of the [http.ServeMux] route matching the request (see [http.Request.Pattern]),
+// without its method. Requests with an unknown method are recorded with the method "other",
+// and requests not matching any route are recorded with the route "unmatched".
+//
+// The middleware can either wrap a [http.ServeMux] or be registered on each of its routes.
+// The pattern is read from the request passed to next, so it isn't visible if next serves a copy of the request
+// to the [http.ServeMux], as [http.StripPrefix] does: the requests are then recorded with the route "unmatched".
+// In that case, register the middleware between the wrapper and the [http.ServeMux], or on each of its routes.
+//
+// Panics if the metric prefix is empty.
+func InstrumentHTTPHandler(next http.Handler, options ...HTTPServerOption) http.Handler {
+	cfg := httpServerConfig{
+		set:    metrics.GetDefaultSet(),
+		prefix: defaultHTTPServerPrefix,
+	}
+	for _, applyOption := range options {
+		applyOption(&cfg)
+	}
+	if len(cfg.prefix) == 0 {
+		panic("vimebu: InstrumentHTTPHandler has been passed an empty metric prefix")
+	}
+
+	h := &httpServerHandler{
+		next:         next,
+		cfg:          cfg,
+		requestsName: cfg.prefix + "_requests_total",
+		durationName: cfg.prefix + "_request_duration_seconds",
+		sizeName:     cfg.prefix + "_response_size_bytes",
+		inFlight:     make(map[string]*InFlightTracker, len(httpMethods)+1),
+	}
+	// The methods are bounded, so the in-flight trackers can be created upfront.
+	inFlightName := cfg.prefix + "_requests_in_flight"
+	for _, method := range httpMethods {
+		h.inFlight[method] = Metric(inFlightName).LabelString(httpMethodLabelName, method).GetOrCreateInFlightTrackerInSet(cfg.set)
+	}
+	h.inFlight[httpOtherMethod] = Metric(inFlightName).LabelString(httpMethodLabelName, httpOtherMethod).GetOrCreateInFlightTrackerInSet(cfg.set)
+	return h
+}
+
+var httpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+type httpServerHandler struct {
+	next http.Handler
+	cfg  httpServerConfig
+
+	requestsName string
+	durationName string
+	sizeName     string
+
+	inFlight map[string]*InFlightTracker
+}
+
+func (h *httpServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := httpMethodEnum.Value(r.Method)
+	done := h.inFlight[method].Start()
+	defer done()
+
+	start := time.Now()
+	rec := &httpResponseRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.next.ServeHTTP(rec, r)
+	duration := time.Since(start).Seconds()
+
+	// The pattern is read after serving the request, as it is set by the ServeMux when wrapping it.
+	route := httpRoute(r.Pattern)
+	h.metric(h.requestsName, method, route, rec.status).GetOrCreateCounterInSet(h.cfg.set).Inc()
+	h.metric(h.durationName, method, route, rec.status).GetOrCreateHistogramInSet(h.cfg.set).Update(duration)
+	h.metric(h.sizeName, method, route, rec.status).GetOrCreateHistogramInSet(h.cfg.set).Update(float64(rec.size))
+}
+
+func (h *httpServerHandler) metric(name, method, route string, status int) *Builder {
+	b := Metric(name).
+		LabelString(httpMethodLabelName, method).
+		LabelStringQuote(httpRouteLabelName, route)
+	if h.cfg.statusClass {
+		return b.LabelStatusClass(httpStatusLabelName, status)
+	}
+	return b.LabelStatusCode(httpStatusLabelName, status)
+}
+
+// httpMethodEnum collapses unknown methods to "other", to bound the label cardinality.
+var httpMethodEnum = NewEnum(httpMethods, WithEnumFallback(httpOtherMethod))
+
+// httpRoute strips the method from a [http.ServeMux] pattern, and collapses
+// requests not matching any pattern to "unmatched".
+func httpRoute(pattern string) string {
+	if len(pattern) == 0 {
+		return httpUnmatchedRoute
+	}
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		return strings.TrimLeft(pattern[i:], " \t")
+	}
+	return pattern
+}
+
+// httpResponseRecorder records the status code and body size of a response.
+type httpResponseRecorder struct {
+	http.ResponseWriter
+
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (r *httpResponseRecorder) WriteHeader(code int) {
+	// Informational responses may precede the final one, they aren't recorded.
+	if !r.wroteHeader && (code >= http.StatusOK || code == http.StatusSwitchingProtocols) {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *httpResponseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Flush implements [http.Flusher], it is a NoOp if the wrapped [http.ResponseWriter] doesn't support flushing.
+func (r *httpResponseRecorder) Flush() {
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack implements [http.Hijacker], allowing connection upgrades such as WebSockets through the middleware.
+// Returns an error wrapping [http.ErrNotSupported] if the wrapped [http.ResponseWriter] doesn't support hijacking.
+func (r *httpResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && !r.wroteHeader {
+		// The response is written by the caller on the hijacked connection, the upgrade is recorded.
+		r.status = http.StatusSwitchingProtocols
+		r.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap allows [http.ResponseController] to access the wrapped [http.ResponseWriter].
+func (r *httpResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
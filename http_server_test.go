@@ -0,0 +1,86 @@
+package vimebu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentHTTPHandler(t *testing.T) {
+	set := metrics.NewSet()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("gopher"))
+	})
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := InstrumentHTTPHandler(mux, WithHTTPServerSet(set), WithHTTPServerPrefix("api"))
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodPost, "/users", nil),
+		httptest.NewRequest(http.MethodGet, "/unknown/path", nil),
+		httptest.NewRequest("BREW", "/users/1", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	out := writeSet(set)
+	require.Contains(t, out, `api_requests_total{method="GET",route="/users/{id}",status="200"} 2`)
+	require.Contains(t, out, `api_requests_total{method="POST",route="/users",status="201"} 1`)
+	require.Contains(t, out, `api_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, out, `api_requests_total{method="other",route="unmatched",status="405"} 1`)
+	require.Contains(t, out, `api_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`)
+	require.Contains(t, out, `api_response_size_bytes_sum{method="GET",route="/users/{id}",status="200"} 12`)
+	require.Contains(t, out, `api_requests_in_flight{method="GET"} 0`)
+}
+
+func TestInstrumentHTTPHandlerStatusClass(t *testing.T) {
+	set := metrics.NewSet()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/teapot", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := InstrumentHTTPHandler(mux, WithHTTPServerSet(set), WithHTTPServerStatusClass())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/teapot", nil))
+
+	require.Contains(t, writeSet(set), `http_server_requests_total{method="GET",route="/teapot",status="4xx"} 1`)
+}
+
+func TestInstrumentHTTPHandlerHijack(t *testing.T) {
+	set := metrics.NewSet()
+	handler := InstrumentHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		_ = rw.Flush()
+	}), WithHTTPServerSet(set))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	require.Contains(t, writeSet(set), `http_server_requests_total{method="GET",route="unmatched",status="101"} 1`)
+
+	// Hijacking isn't supported by the recorder of httptest.
+	rec := &httpResponseRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	_, _, err = rec.Hijack()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+}
@@ -0,0 +1,62 @@
+package vimebu
+
+import (
+	"sync/atomic"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// InFlightTracker tracks the number of operations currently in progress using a [metrics.Gauge].
+//
+// Its labels are finalized when it is created from a [Builder], it can't be modified afterwards.
+//
+// [InFlightTracker] instances are safe to use from concurrently running goroutines.
+type InFlightTracker struct {
+	gauge *metrics.Gauge
+}
+
+// Start increments the underlying gauge and returns a function decrementing it.
+//
+// The returned function is idempotent, only its first call will decrement the gauge.
+// This makes it safe to use with defer, even if it was already called explicitly :
+//
+//	done := tracker.Start()
+//	defer done()
+func (t *InFlightTracker) Start() (done func()) {
+	t.gauge.Inc()
+	var called atomic.Bool
+	return func() {
+		if called.CompareAndSwap(false, true) {
+			t.gauge.Dec()
+		}
+	}
+}
+
+// Gauge returns the [metrics.Gauge] backing the [InFlightTracker].
+func (t *InFlightTracker) Gauge() *metrics.Gauge {
+	return t.gauge
+}
+
+// GetOrCreateInFlightTracker calls [metrics.GetOrCreateGauge] using the Builder's accumulated string as argument,
+// and wraps the resulting gauge in an [InFlightTracker].
+func (b *Builder) GetOrCreateInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{gauge: b.GetOrCreateGauge(nil)}
+}
+
+// GetOrCreateInFlightTrackerInSet calls [metrics.Set.GetOrCreateGauge] using the Builder's accumulated string as argument,
+// and wraps the resulting gauge in an [InFlightTracker].
+func (b *Builder) GetOrCreateInFlightTrackerInSet(set *metrics.Set) *InFlightTracker {
+	return &InFlightTracker{gauge: b.GetOrCreateGaugeInSet(set, nil)}
+}
+
+// NewInFlightTracker calls [metrics.NewGauge] using the Builder's accumulated string as argument,
+// and wraps the resulting gauge in an [InFlightTracker].
+func (b *Builder) NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{gauge: b.NewGauge(nil)}
+}
+
+// NewInFlightTrackerInSet calls [metrics.Set.NewGauge] using the Builder's accumulated string as argument,
+// and wraps the resulting gauge in an [InFlightTracker].
+func (b *Builder) NewInFlightTrackerInSet(set *metrics.Set) *InFlightTracker {
+	return &InFlightTracker{gauge: b.NewGaugeInSet(set, nil)}
+}
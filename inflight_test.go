@@ -0,0 +1,45 @@
+package vimebu
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightTracker(t *testing.T) {
+	set := metrics.NewSet()
+	tracker := Metric("requests_in_flight").LabelString("path", "/foo").GetOrCreateInFlightTrackerInSet(set)
+
+	first := tracker.Start()
+	second := tracker.Start()
+	require.Equal(t, float64(2), tracker.Gauge().Get())
+
+	first()
+	first() // Idempotent, must not decrement the gauge a second time.
+	require.Equal(t, float64(1), tracker.Gauge().Get())
+
+	second()
+	require.Equal(t, float64(0), tracker.Gauge().Get())
+
+	// Same series, same gauge.
+	same := Metric("requests_in_flight").LabelString("path", "/foo").GetOrCreateInFlightTrackerInSet(set)
+	require.Same(t, tracker.Gauge(), same.Gauge())
+}
+
+func TestInFlightTrackerParallel(t *testing.T) {
+	set := metrics.NewSet()
+	tracker := Metric("jobs_in_flight").NewInFlightTrackerInSet(set)
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			done := tracker.Start()
+			defer done()
+			done()
+		})
+	}
+	wg.Wait()
+	require.Equal(t, float64(0), tracker.Gauge().Get())
+}
@@ -0,0 +1,86 @@
+package vimebu
+
+import (
+	"context"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+const (
+	instrumentCallsSuffix    string = "_calls_total"
+	instrumentErrorsSuffix   string = "_errors_total"
+	instrumentDurationSuffix string = "_duration_seconds"
+)
+
+// instrumentation holds the metrics recorded by the functions returned by [Instrument] and its variations.
+type instrumentation struct {
+	set        *metrics.Set
+	calls      *metrics.Counter
+	duration   *metrics.Histogram
+	errorsName string
+}
+
+func newInstrumentation(set *metrics.Set, prefix string) *instrumentation {
+	if len(prefix) == 0 {
+		panic("vimebu: Instrument has been passed an empty metric prefix")
+	}
+	return &instrumentation{
+		set:        set,
+		calls:      Metric(prefix + instrumentCallsSuffix).GetOrCreateCounterInSet(set),
+		duration:   Metric(prefix + instrumentDurationSuffix).GetOrCreateHistogramInSet(set),
+		errorsName: prefix + instrumentErrorsSuffix,
+	}
+}
+
+func (i *instrumentation) observe(start time.Time, err error) {
+	i.calls.Inc()
+	i.duration.UpdateDuration(start)
+	if err != nil {
+		Metric(i.errorsName).LabelErrorQuote(err).GetOrCreateCounterInSet(i.set).Inc()
+	}
+}
+
+// Instrument wraps fn so that each of its calls is recorded in the default [metrics.Set] using the following metrics :
+//
+//   - <prefix>_calls_total : counter incremented for each call
+//   - <prefix>_errors_total{error="..."} : counter incremented for each call returning a non-nil error, see [Builder.LabelErrorQuote]
+//   - <prefix>_duration_seconds : histogram of the calls duration
+//
+// Panics if prefix is empty.
+func Instrument(prefix string, fn func(context.Context) error) func(context.Context) error {
+	return InstrumentInSet(metrics.GetDefaultSet(), prefix, fn)
+}
+
+// InstrumentInSet works like [Instrument], but records the metrics in the provided [metrics.Set].
+//
+// Panics if prefix is empty.
+func InstrumentInSet(set *metrics.Set, prefix string, fn func(context.Context) error) func(context.Context) error {
+	inst := newInstrumentation(set, prefix)
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := fn(ctx)
+		inst.observe(start, err)
+		return err
+	}
+}
+
+// InstrumentValue works like [Instrument], for functions returning a value alongside the error.
+//
+// Panics if prefix is empty.
+func InstrumentValue[T any](prefix string, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return InstrumentValueInSet(metrics.GetDefaultSet(), prefix, fn)
+}
+
+// InstrumentValueInSet works like [InstrumentValue], but records the metrics in the provided [metrics.Set].
+//
+// Panics if prefix is empty.
+func InstrumentValueInSet[T any](set *metrics.Set, prefix string, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	inst := newInstrumentation(set, prefix)
+	return func(ctx context.Context) (T, error) {
+		start := time.Now()
+		value, err := fn(ctx)
+		inst.observe(start, err)
+		return value, err
+	}
+}
@@ -0,0 +1,58 @@
+package vimebu
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSet(set *metrics.Set) string {
+	var buf bytes.Buffer
+	set.WritePrometheus(&buf)
+	return buf.String()
+}
+
+func TestInstrument(t *testing.T) {
+	set := metrics.NewSet()
+	fail := true
+	fn := InstrumentInSet(set, "db_ping", func(context.Context) error {
+		if fail {
+			return errors.New(`connection "reset"`)
+		}
+		return nil
+	})
+
+	require.Error(t, fn(t.Context()))
+	fail = false
+	require.NoError(t, fn(t.Context()))
+
+	out := writeSet(set)
+	require.Contains(t, out, "db_ping_calls_total 2\n")
+	require.Contains(t, out, `db_ping_errors_total{error="connection \"reset\""} 1`+"\n")
+	require.Contains(t, out, "db_ping_duration_seconds_count 2\n")
+}
+
+func TestInstrumentValue(t *testing.T) {
+	set := metrics.NewSet()
+	fn := InstrumentValueInSet(set, "user_get", func(context.Context) (string, error) {
+		return "gopher", nil
+	})
+
+	value, err := fn(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "gopher", value)
+
+	out := writeSet(set)
+	require.Contains(t, out, "user_get_calls_total 1\n")
+	require.NotContains(t, out, "user_get_errors_total")
+}
+
+func TestInstrumentEmptyPrefix(t *testing.T) {
+	require.Panics(t, func() {
+		InstrumentInSet(metrics.NewSet(), "", func(context.Context) error { return nil })
+	})
+}
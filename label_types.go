@@ -0,0 +1,161 @@
+package vimebu
+
+import (
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// LabelDuration adds a label with a value of type [time.Duration] to the [Builder],
+// expressed as a number of the provided unit (e.g. "1.5" for 1500ms expressed in [time.Second]).
+//
+// If unit isn't positive, the value is expressed in seconds.
+//
+// NoOp if the label name is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelDuration(name string, value, unit time.Duration) *Builder {
+	if unit <= 0 {
+		unit = time.Second
+	}
+	return b.labelAppender(name, func(dst []byte) ([]byte, error) {
+		return strconv.AppendFloat(dst, float64(value)/float64(unit), floatFormattingVerb, floatShortestPrecision, floatBitSize), nil
+	})
+}
+
+// LabelTime adds a label with a value of type [time.Time] to the [Builder], formatted using the provided layout
+// after being truncated to a multiple of truncate (see [time.Time.Truncate]). Truncating the value allows
+// bounding the label cardinality, for example to the day or the hour.
+//
+// If layout is empty, [time.RFC3339] is used.
+//
+// NoOp if the label name is empty, or if value is the zero time.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelTime(name string, value time.Time, layout string, truncate time.Duration) *Builder {
+	if value.IsZero() {
+		return b
+	}
+	if len(layout) == 0 {
+		layout = time.RFC3339
+	}
+	return b.labelAppender(name, func(dst []byte) ([]byte, error) {
+		return value.Truncate(truncate).AppendFormat(dst, layout), nil
+	})
+}
+
+// LabelAddr adds a label with a value of type [netip.Addr] to the [Builder].
+//
+// NoOp if the label name is empty, or if value is invalid (e.g. the zero value).
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelAddr(name string, value netip.Addr) *Builder {
+	if !value.IsValid() {
+		return b
+	}
+	return b.labelAppender(name, func(dst []byte) ([]byte, error) {
+		return value.AppendTo(dst), nil
+	})
+}
+
+// LabelAddrMasked adds a label with a value of type [netip.Addr] to the [Builder], masked to its first
+// ipv4Bits or ipv6Bits bits depending on its family, and formatted as a prefix (e.g. "192.168.1.0/24").
+// IPv4-mapped IPv6 addresses are handled as IPv4 addresses.
+//
+// Masking addresses (typically to /24 and /64) hides the client identity and bounds the label cardinality.
+//
+// NoOp if the label name is empty, or if value is invalid (e.g. the zero value).
+// If the number of bits is out of range for the address family, a log line containing the reason will
+// be written to [os.Stderr], and the label will be skipped.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelAddrMasked(name string, value netip.Addr, ipv4Bits, ipv6Bits int) *Builder {
+	if !value.IsValid() {
+		return b
+	}
+	value = value.Unmap()
+	bits := ipv6Bits
+	if value.Is4() {
+		bits = ipv4Bits
+	}
+	return b.labelAppender(name, func(dst []byte) ([]byte, error) {
+		prefix, err := value.Prefix(bits)
+		if err != nil {
+			return dst, err
+		}
+		return prefix.AppendTo(dst), nil
+	})
+}
+
+// LabelPrefix adds a label with a value of type [netip.Prefix] to the [Builder].
+//
+// NoOp if the label name is empty, or if value is invalid (e.g. the zero value).
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelPrefix(name string, value netip.Prefix) *Builder {
+	if !value.IsValid() {
+		return b
+	}
+	return b.labelAppender(name, func(dst []byte) ([]byte, error) {
+		return value.AppendTo(dst), nil
+	})
+}
+
+// LabelURLHost adds a label with the host (and port, if any) of a [url.URL] to the [Builder].
+// Quotes inside label value will be escaped using [strconv.AppendQuote].
+//
+// NoOp if the label name is empty, if value is nil, or if its host is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelURLHost(name string, value *url.URL) *Builder {
+	if value == nil || len(value.Host) == 0 {
+		return b
+	}
+	return b.LabelStringQuote(name, value.Host)
+}
+
+// LabelURLPath adds a label with the escaped path of a [url.URL] to the [Builder] (see [url.URL.EscapedPath]).
+//
+// Paths often embed identifiers, consider registering [NormalizePathTemplate] on the [BuilderPool]
+// using [WithLabelNormalizers] to bound the label cardinality.
+//
+// NoOp if the label name is empty, if value is nil, or if its path is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelURLPath(name string, value *url.URL) *Builder {
+	if value == nil || (len(value.Path) == 0 && len(value.RawPath) == 0) {
+		return b
+	}
+	return b.LabelString(name, value.EscapedPath())
+}
+
+// LabelStatusCode adds a label with an HTTP status code to the [Builder] (e.g. "404").
+//
+// NoOp if the label name is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelStatusCode(name string, code int) *Builder {
+	return b.LabelInt(name, code)
+}
+
+// LabelStatusClass adds a label with the class of an HTTP status code to the [Builder] (e.g. "4xx"),
+// or "unknown" if the code is out of the [100, 599] range.
+//
+// NoOp if the label name is empty.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelStatusClass(name string, code int) *Builder {
+	return b.LabelString(name, httpStatusClass(code))
+}
+
+var httpStatusClasses = [...]string{"1xx", "2xx", "3xx", "4xx", "5xx"}
+
+// httpStatusClass returns the class of a status code (e.g. "2xx"), or "unknown"
+// if the code is out of the [100, 599] range.
+func httpStatusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return httpStatusClasses[code/100-1]
+}
@@ -0,0 +1,66 @@
+package vimebu
+
+import (
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderLabelTimeAndDuration(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	metric := Metric("test_types").
+		LabelDuration("seconds", 1500*time.Millisecond, time.Second).
+		LabelDuration("millis", 1500*time.Millisecond, time.Millisecond).
+		LabelDuration("default", 2*time.Minute, 0).
+		LabelTime("day", at, time.DateOnly, 24*time.Hour).
+		LabelTime("hour", at, "", time.Hour).
+		LabelTime("zero", time.Time{}, "", 0).
+		String()
+	require.Equal(t, `test_types{seconds="1.5",millis="1500",default="120",day="2024-01-02",hour="2024-01-02T03:00:00Z"}`, metric)
+}
+
+func TestBuilderLabelNetworkTypes(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("test_types").
+			LabelAddr("ip", netip.MustParseAddr("10.1.2.3")).
+			LabelAddr("invalid", netip.Addr{}).
+			LabelAddrMasked("v4", netip.MustParseAddr("192.168.1.42"), 24, 64).
+			LabelAddrMasked("mapped", netip.MustParseAddr("::ffff:192.168.1.42"), 24, 64).
+			LabelAddrMasked("v6", netip.MustParseAddr("2001:db8:1:2:3:4:5:6"), 24, 64).
+			LabelAddrMasked("bad_bits", netip.MustParseAddr("192.168.1.42"), 33, 64). // Will be skipped.
+			LabelPrefix("prefix", netip.MustParsePrefix("10.0.0.0/8")).
+			LabelPrefix("invalid_prefix", netip.Prefix{}).
+			String()
+		require.Equal(t, `test_types{ip="10.1.2.3",v4="192.168.1.0/24",mapped="192.168.1.0/24",v6="2001:db8:1:2::/64",prefix="10.0.0.0/8"}`, metric)
+	})
+	require.Len(t, logLines, 1)
+}
+
+func TestBuilderLabelURL(t *testing.T) {
+	u, err := url.Parse("https://api.example.com:8443/users/some%2Fid?q=1")
+	require.NoError(t, err)
+
+	metric := Metric("test_types").
+		LabelURLHost("host", u).
+		LabelURLPath("path", u).
+		LabelURLHost("nil", nil).
+		LabelURLPath("empty", &url.URL{}).
+		String()
+	require.Equal(t, `test_types{host="api.example.com:8443",path="/users/some%2Fid"}`, metric)
+}
+
+func TestBuilderLabelStatus(t *testing.T) {
+	metric := Metric("test_types").LabelStatusCode("code", 404).LabelStatusClass("class", 404).LabelStatusClass("unknown", 42).String()
+	require.Equal(t, `test_types{code="404",class="4xx",unknown="unknown"}`, metric)
+}
+
+func TestHTTPStatusClass(t *testing.T) {
+	require.Equal(t, "1xx", httpStatusClass(101))
+	require.Equal(t, "2xx", httpStatusClass(204))
+	require.Equal(t, "5xx", httpStatusClass(599))
+	require.Equal(t, "unknown", httpStatusClass(99))
+	require.Equal(t, "unknown", httpStatusClass(600))
+}
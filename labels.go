@@ -0,0 +1,34 @@
+package vimebu
+
+// Label represents a label name and value pair.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Labels is an ordered list of [Label].
+type Labels []Label
+
+// Get returns the value of the first label with the provided name, and whether it was found.
+func (l Labels) Get(name string) (string, bool) {
+	for _, label := range l {
+		if label.Name == name {
+			return label.Value, true
+		}
+	}
+	return "", false
+}
+
+// Labels adds each of the provided labels to the [Builder], in order.
+// Quotes inside label values will be escaped using [strconv.AppendQuote].
+//
+// Labels with an empty name are skipped, as well as labels with an empty value unless a placeholder is set
+// (see [WithEmptyLabelPlaceholder]).
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) Labels(labels Labels) *Builder {
+	for _, label := range labels {
+		b.LabelStringQuote(label.Name, label.Value)
+	}
+	return b
+}
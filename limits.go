@@ -0,0 +1,142 @@
+package vimebu
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"unicode/utf8"
+)
+
+// LimitPolicy defines how a [Builder] handles the labels exceeding the limits set using
+// [WithMaxLabels] and [WithMaxSeriesLen].
+type LimitPolicy uint8
+
+const (
+	// LimitDrop skips the labels exceeding the limits, keeping the labels added before them.
+	// A log line containing the reason will be written to [os.Stderr].
+	LimitDrop LimitPolicy = iota
+	// LimitTruncate truncates the value of the label exceeding the series length limit so that it fits,
+	// keeping quoted values valid. The label is skipped if its value can't fit at all, and labels exceeding
+	// the count limit are skipped as with [LimitDrop].
+	// A log line containing the reason will be written to [os.Stderr].
+	LimitTruncate
+	// LimitPanic panics when a label exceeds the limits.
+	LimitPanic
+)
+
+// WithMaxLabels sets the max number of labels of the series, matching the -maxLabelsPerTimeseries
+// flag of VictoriaMetrics.
+//
+// Zero means no limit.
+//
+// The labels exceeding it are handled according to the policy set using [WithLimitPolicy].
+func WithMaxLabels(maxLabels int) BuilderOption {
+	return func(b *Builder) {
+		b.maxLabels = maxLabels
+	}
+}
+
+// WithMaxSeriesLen sets the max length of the series, including the metric name and the labels.
+//
+// Zero means no limit.
+//
+// The labels exceeding it are handled according to the policy set using [WithLimitPolicy].
+// If the metric name alone exceeds it, the metric is dropped (see [Builder.String]), unless the policy is [LimitPanic].
+func WithMaxSeriesLen(maxLen int) BuilderOption {
+	return func(b *Builder) {
+		b.maxSeriesLen = maxLen
+	}
+}
+
+// WithLimitPolicy sets how the labels exceeding the limits set using [WithMaxLabels] and [WithMaxSeriesLen]
+// are handled.
+//
+// Defaults to [LimitDrop].
+func WithLimitPolicy(policy LimitPolicy) BuilderOption {
+	return func(b *Builder) {
+		b.limitPolicy = policy
+	}
+}
+
+// isWithinMetricNameLimit checks if the metric name fits in the series length limit.
+func (b *Builder) isWithinMetricNameLimit(name string) bool {
+	if b.maxSeriesLen == 0 || len(name) <= b.maxSeriesLen {
+		return true
+	}
+	if b.limitPolicy == LimitPanic {
+		panic(fmt.Sprintf("vimebu: metric %q len exceeds set limit of %d", name, b.maxSeriesLen))
+	}
+	log.Printf("vimebu: metric %q len exceeds set limit of %d - dropping", name, b.maxSeriesLen)
+	return false
+}
+
+// isWithinLimits checks if the label appended at the end of the buffer, starting at start, fits in the limits.
+// The label may be truncated, or rolled back if it doesn't.
+func (b *Builder) isWithinLimits(start, nameLen int, quoted bool) bool {
+	name := b.buf[start+1 : start+1+nameLen]
+	if b.maxLabels > 0 && len(b.labels) >= b.maxLabels {
+		if b.limitPolicy == LimitPanic {
+			panic(fmt.Sprintf("vimebu: metric %q, label name %q exceeds set limit of %d labels", b.buf[:start], name, b.maxLabels))
+		}
+		log.Printf("vimebu: metric %q, label name %q exceeds set limit of %d labels - skipping", b.buf[:start], name, b.maxLabels)
+		b.buf = b.buf[:start]
+		return false
+	}
+	// The closing bracket counts in the series length.
+	if b.maxSeriesLen == 0 || len(b.buf)+1 <= b.maxSeriesLen {
+		return true
+	}
+	switch b.limitPolicy {
+	case LimitPanic:
+		panic(fmt.Sprintf("vimebu: metric %q, label name %q, series len exceeds set limit of %d", b.buf[:start], name, b.maxSeriesLen))
+	case LimitTruncate:
+		if b.truncateLabelValue(start+1+nameLen+1, quoted) {
+			log.Printf("vimebu: metric %q, label name %q, series len exceeds set limit of %d - truncating", b.buf[:start], name, b.maxSeriesLen)
+			return true
+		}
+	}
+	log.Printf("vimebu: metric %q, label name %q, series len exceeds set limit of %d - skipping", b.buf[:start], name, b.maxSeriesLen)
+	b.buf = b.buf[:start]
+	return false
+}
+
+// truncateLabelValue truncates the label value appended at the end of the buffer, starting with its opening
+// double quote at valueStart, so that the series fits in the length limit.
+//
+// Returns false if the value can't be truncated to a non-empty value.
+func (b *Builder) truncateLabelValue(valueStart int, quoted bool) bool {
+	// Room left for the value, without its double quotes and the closing bracket.
+	room := b.maxSeriesLen - valueStart - 3
+	if room <= 0 {
+		return false
+	}
+	if !quoted {
+		value := b.buf[valueStart+1 : len(b.buf)-1]
+		n := room
+		for n > 0 && !utf8.RuneStart(value[n]) { // Don't cut a multi-byte rune.
+			n--
+		}
+		if n == 0 {
+			return false
+		}
+		b.buf = append(b.buf[:valueStart+1+n], doubleQuotesByte)
+		return true
+	}
+	// Quoted values are unquoted and quoted again, to avoid cutting an escape sequence.
+	value, err := strconv.Unquote(string(b.buf[valueStart:]))
+	if err != nil {
+		return false
+	}
+	if len(value) > room {
+		value = value[:room]
+	}
+	for len(value) > 0 {
+		if buf := strconv.AppendQuote(b.buf[:valueStart], value); len(buf)+1 <= b.maxSeriesLen {
+			b.buf = buf
+			return true
+		}
+		_, size := utf8.DecodeLastRuneInString(value)
+		value = value[:len(value)-size]
+	}
+	return false
+}
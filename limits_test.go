@@ -0,0 +1,94 @@
+package vimebu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderOptionsWithMaxLabels(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("requests_total", WithMaxLabels(2)).
+			LabelString("method", "GET").
+			LabelInt("status", 200).
+			LabelString("path", "/").
+			LabelBool("cached", true).
+			String()
+		require.Equal(t, `requests_total{method="GET",status="200"}`, metric)
+	})
+	require.Len(t, logLines, 2)
+	require.Contains(t, logLines[0], `label name "path" exceeds set limit of 2 labels - skipping`)
+
+	require.Panics(t, func() {
+		b := AcquireBuilder()
+		defer ReleaseBuilder(b)
+		b.Metric("requests_total", WithMaxLabels(1), WithLimitPolicy(LimitPanic)).LabelString("a", "1").LabelString("b", "2")
+	})
+}
+
+func TestBuilderOptionsWithMaxSeriesLen(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("requests_total", WithMaxSeriesLen(34)).
+			LabelString("method", "GET").
+			LabelString("path", "/users").
+			LabelString("a", "b").
+			String()
+		require.Equal(t, `requests_total{method="GET",a="b"}`, metric)
+		require.Len(t, metric, 34)
+	})
+	require.Len(t, logLines, 1)
+	require.Contains(t, logLines[0], `label name "path", series len exceeds set limit of 34 - skipping`)
+
+	logLines = captureLogOutput(func() {
+		require.Empty(t, Metric("requests_total", WithMaxSeriesLen(8)).LabelString("a", "b").String())
+	})
+	require.Len(t, logLines, 2)
+
+	require.Panics(t, func() {
+		b := AcquireBuilder()
+		defer ReleaseBuilder(b)
+		b.Metric("requests_total", WithMaxSeriesLen(20), WithLimitPolicy(LimitPanic)).LabelString("method", "GET")
+	})
+}
+
+func TestBuilderOptionsWithLimitPolicyTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		build    func(b *Builder) *Builder
+		expected string
+	}{
+		{
+			name:     "raw",
+			build:    func(b *Builder) *Builder { return b.LabelString("path", "/users/1234") },
+			expected: `requests_total{path="/users/1"}`,
+		},
+		{
+			name:     "multi-byte rune",
+			build:    func(b *Builder) *Builder { return b.LabelString("path", "/usérs/1234") },
+			expected: `requests_total{path="/usérs/"}`,
+		},
+		{
+			name:     "escape sequence",
+			build:    func(b *Builder) *Builder { return b.LabelStringQuote("path", `/user"s/1234`) },
+			expected: `requests_total{path="/user\"s"}`,
+		},
+		{
+			name:     "integer",
+			build:    func(b *Builder) *Builder { return b.LabelInt("path", 1234567890123) },
+			expected: `requests_total{path="12345678"}`,
+		},
+		{
+			name:     "no room left",
+			build:    func(b *Builder) *Builder { return b.LabelString("path", "/users/").LabelString("a", "b") },
+			expected: `requests_total{path="/users/"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			captureLogOutput(func() {
+				metric := tc.build(Metric("requests_total", WithMaxSeriesLen(31), WithLimitPolicy(LimitTruncate))).String()
+				require.Equal(t, tc.expected, metric)
+				require.LessOrEqual(t, len(metric), 31)
+			})
+		})
+	}
+}
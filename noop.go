@@ -0,0 +1,32 @@
+package vimebu
+
+import (
+	"sync"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// noopSet holds the metrics returned for dropped metrics. It is never written to any output,
+// so its metrics can be shared and updated freely.
+var noopSet = sync.OnceValue(metrics.NewSet)
+
+func noopCounter() *metrics.Counter {
+	return noopSet().GetOrCreateCounter("vimebu_noop_counter")
+}
+
+func noopFloatCounter() *metrics.FloatCounter {
+	return noopSet().GetOrCreateFloatCounter("vimebu_noop_float_counter")
+}
+
+func noopHistogram() *metrics.Histogram {
+	return noopSet().GetOrCreateHistogram("vimebu_noop_histogram")
+}
+
+// noopGauge is created without callback, so that it can be updated using [metrics.Gauge.Set].
+func noopGauge() *metrics.Gauge {
+	return noopSet().GetOrCreateGauge("vimebu_noop_gauge", nil)
+}
+
+func noopSummary() *metrics.Summary {
+	return noopSet().GetOrCreateSummary("vimebu_noop_summary")
+}
@@ -0,0 +1,102 @@
+package vimebu
+
+import (
+	"regexp"
+	"strings"
+)
+
+const pathTemplatePlaceholder string = ":id"
+
+// Normalizer transforms a label value before it is added to a [Builder], see [WithLabelNormalizers].
+type Normalizer func(value string) string
+
+// NormalizeLower is a [Normalizer] lower casing label values.
+func NormalizeLower(value string) string {
+	return strings.ToLower(value)
+}
+
+// NormalizeTrimSpace is a [Normalizer] removing the leading and trailing white spaces of label values.
+func NormalizeTrimSpace(value string) string {
+	return strings.TrimSpace(value)
+}
+
+// NormalizePathTemplate is a [Normalizer] replacing the segments of a path looking like
+// identifiers with ":id", so that e.g. "/users/123" and "/users/124" both become "/users/:id".
+//
+// Segments made of digits, UUIDs, and hexadecimal strings of at least 8 characters containing
+// at least one digit are considered identifiers.
+func NormalizePathTemplate(value string) string {
+	var (
+		sb      strings.Builder
+		last    int
+		changed bool
+	)
+	for start := 0; start <= len(value); {
+		end := strings.IndexByte(value[start:], '/')
+		if end < 0 {
+			end = len(value)
+		} else {
+			end += start
+		}
+		if isIdentifierSegment(value[start:end]) {
+			if !changed { // Only allocate when the path needs to be templated.
+				sb.Grow(len(value))
+				changed = true
+			}
+			sb.WriteString(value[last:start])
+			sb.WriteString(pathTemplatePlaceholder)
+			last = end
+		}
+		start = end + 1
+	}
+	if !changed {
+		return value
+	}
+	sb.WriteString(value[last:])
+	return sb.String()
+}
+
+func isIdentifierSegment(segment string) bool {
+	if len(segment) == 0 {
+		return false
+	}
+	if isDigits(segment) || isUUID(segment) {
+		return true
+	}
+	return len(segment) >= 8 && isHex(segment) && strings.ContainsAny(segment, "0123456789")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s is formatted as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func isUUID(s string) bool {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return false
+	}
+	return isHex(s[:8]) && isHex(s[9:13]) && isHex(s[14:18]) && isHex(s[19:23]) && isHex(s[24:])
+}
+
+// NormalizeRegexp returns a [Normalizer] replacing the matches of re in label values with
+// replacement, see [regexp.Regexp.ReplaceAllString].
+func NormalizeRegexp(re *regexp.Regexp, replacement string) Normalizer {
+	return func(value string) string {
+		return re.ReplaceAllString(value, replacement)
+	}
+}
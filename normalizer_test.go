@@ -0,0 +1,50 @@
+package vimebu
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePathTemplate(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":                       "",
+		"/":                      "/",
+		"/users":                 "/users",
+		"/users/123":             "/users/:id",
+		"/users/123/orders":      "/users/:id/orders",
+		"/users/123/orders/456/": "/users/:id/orders/:id/",
+		"/items/3f2504e0-4f89-11d3-9a0c-0305e82c3301": "/items/:id",
+		"/blobs/deadbeef42":                           "/blobs/:id",
+		"/blobs/deadbeef":                             "/blobs/deadbeef",
+		"/v2/api":                                     "/v2/api",
+		"42":                                          ":id",
+	} {
+		require.Equal(t, expected, NormalizePathTemplate(input), input)
+	}
+}
+
+func TestBuilderPoolLabelNormalizers(t *testing.T) {
+	pool := NewBuilderPool(
+		WithLabelNormalizers("path", NormalizeTrimSpace, NormalizePathTemplate),
+		WithLabelNormalizers("method", NormalizeLower),
+		WithLabelNormalizers("email", NormalizeRegexp(regexp.MustCompile(`^.*@`), "*@")),
+	)
+
+	metric := pool.Metric("requests_total").
+		LabelString("path", " /users/123 ").
+		LabelString("method", "GET").
+		LabelStringQuote("email", "gopher@example.com").
+		LabelString("other", "GET").
+		String()
+	require.Equal(t, `requests_total{path="/users/:id",method="get",email="*@example.com",other="GET"}`, metric)
+
+	// Builders acquired from the pool are also normalized.
+	builder := pool.Acquire()
+	defer pool.Release(builder)
+	require.Equal(t, `requests_total{method="post"}`, builder.Metric("requests_total").LabelString("method", "POST").String())
+
+	// Other pools aren't affected.
+	require.Equal(t, `requests_total{method="GET"}`, Metric("requests_total").LabelString("method", "GET").String())
+}
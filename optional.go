@@ -0,0 +1,216 @@
+package vimebu
+
+// LabelIf adds a label with a value of type string to the [Builder] if cond is true, see [Builder.LabelString].
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelIf(cond bool, name, value string) *Builder {
+	if !cond {
+		return b.skipLabel()
+	}
+	return b.LabelString(name, value)
+}
+
+// LabelFunc adds a label with a value of type string returned by f to the [Builder], see [Builder.LabelString].
+// f is only called if the label can be added, which avoids computing the value of labels skipped
+// because of their name, or of dropped metrics.
+//
+// NoOp if the label name is empty, or if f returns an empty string and no placeholder is set (see [WithEmptyLabelPlaceholder]).
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelFunc(name string, f func() string) *Builder {
+	if !b.hasFlag(flagHasMetricName) {
+		panic("vimebu: can't add a label to a Builder with no metric name")
+	}
+	if b.hasFlag(flagDropped) || !b.isValidLabelName(name) {
+		return b
+	}
+	return b.LabelString(name, f())
+}
+
+// LabelStringPtr adds a label with a value of type *string to the [Builder], see [Builder.LabelString].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelStringPtr(name string, value *string) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelString(name, *value)
+}
+
+// LabelStringQuotePtr adds a label with a value of type *string to the [Builder], see [Builder.LabelStringQuote].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelStringQuotePtr(name string, value *string) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelStringQuote(name, *value)
+}
+
+// LabelBoolPtr adds a label with a value of type *bool to the [Builder], see [Builder.LabelBool].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelBoolPtr(name string, value *bool) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelBool(name, *value)
+}
+
+// LabelUintPtr adds a label with a value of type *uint to the [Builder], see [Builder.LabelUint].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelUintPtr(name string, value *uint) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelUint(name, *value)
+}
+
+// LabelUint8Ptr adds a label with a value of type *uint8 to the [Builder], see [Builder.LabelUint8].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelUint8Ptr(name string, value *uint8) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelUint8(name, *value)
+}
+
+// LabelUint16Ptr adds a label with a value of type *uint16 to the [Builder], see [Builder.LabelUint16].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelUint16Ptr(name string, value *uint16) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelUint16(name, *value)
+}
+
+// LabelUint32Ptr adds a label with a value of type *uint32 to the [Builder], see [Builder.LabelUint32].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelUint32Ptr(name string, value *uint32) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelUint32(name, *value)
+}
+
+// LabelUint64Ptr adds a label with a value of type *uint64 to the [Builder], see [Builder.LabelUint64].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelUint64Ptr(name string, value *uint64) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelUint64(name, *value)
+}
+
+// LabelIntPtr adds a label with a value of type *int to the [Builder], see [Builder.LabelInt].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelIntPtr(name string, value *int) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelInt(name, *value)
+}
+
+// LabelInt8Ptr adds a label with a value of type *int8 to the [Builder], see [Builder.LabelInt8].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelInt8Ptr(name string, value *int8) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelInt8(name, *value)
+}
+
+// LabelInt16Ptr adds a label with a value of type *int16 to the [Builder], see [Builder.LabelInt16].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelInt16Ptr(name string, value *int16) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelInt16(name, *value)
+}
+
+// LabelInt32Ptr adds a label with a value of type *int32 to the [Builder], see [Builder.LabelInt32].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelInt32Ptr(name string, value *int32) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelInt32(name, *value)
+}
+
+// LabelInt64Ptr adds a label with a value of type *int64 to the [Builder], see [Builder.LabelInt64].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelInt64Ptr(name string, value *int64) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelInt64(name, *value)
+}
+
+// LabelFloat32Ptr adds a label with a value of type *float32 to the [Builder], see [Builder.LabelFloat32].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelFloat32Ptr(name string, value *float32) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelFloat32(name, *value)
+}
+
+// LabelFloat64Ptr adds a label with a value of type *float64 to the [Builder], see [Builder.LabelFloat64].
+//
+// NoOp if the label name is empty, or if value is nil.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelFloat64Ptr(name string, value *float64) *Builder {
+	if value == nil {
+		return b.skipLabel()
+	}
+	return b.LabelFloat64(name, *value)
+}
+
+// skipLabel skips a label, panicking like the methods adding one if [Builder.Metric] hasn't been called.
+func (b *Builder) skipLabel() *Builder {
+	if !b.hasFlag(flagHasMetricName) {
+		panic("vimebu: can't add a label to a Builder with no metric name")
+	}
+	return b
+}
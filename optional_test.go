@@ -0,0 +1,94 @@
+package vimebu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderLabelIf(t *testing.T) {
+	host := ""
+	metric := Metric("requests_total").
+		LabelIf(len(host) > 0, "host", host).
+		LabelIf(true, "method", "GET").
+		String()
+	require.Equal(t, `requests_total{method="GET"}`, metric)
+
+	require.Panics(t, func() {
+		var b Builder
+		b.LabelIf(false, "host", host)
+	})
+}
+
+func TestBuilderLabelFunc(t *testing.T) {
+	var calls int
+	f := func() string {
+		calls++
+		return "value"
+	}
+
+	pool := NewBuilderPool(WithLabelAllowlist("requests_total", "allowed"))
+	pool.SetPolicy(&Policy{Metrics: map[string]MetricPolicy{"disabled_total": {Disabled: true}}})
+
+	captureLogOutput(func() {
+		metric := pool.Metric("requests_total").
+			LabelFunc("allowed", f).
+			LabelFunc("other", f).
+			LabelFunc("", f).
+			String()
+		require.Equal(t, `requests_total{allowed="value"}`, metric)
+		require.Empty(t, pool.Metric("disabled_total").LabelFunc("allowed", f).String())
+	})
+	require.Equal(t, 1, calls)
+}
+
+func TestBuilderLabelPtr(t *testing.T) {
+	var (
+		s   = "value"
+		q   = `"quoted"`
+		bl  = true
+		u   = uint(1)
+		u8  = uint8(8)
+		u16 = uint16(16)
+		u32 = uint32(32)
+		u64 = uint64(64)
+		i   = -1
+		i8  = int8(-8)
+		i16 = int16(-16)
+		i32 = int32(-32)
+		i64 = int64(-64)
+		f32 = float32(3.5)
+		f64 = 6.25
+	)
+	metric := Metric("test").
+		LabelStringPtr("s", &s).
+		LabelStringQuotePtr("q", &q).
+		LabelBoolPtr("bl", &bl).
+		LabelUintPtr("u", &u).
+		LabelUint8Ptr("u8", &u8).
+		LabelUint16Ptr("u16", &u16).
+		LabelUint32Ptr("u32", &u32).
+		LabelUint64Ptr("u64", &u64).
+		LabelIntPtr("i", &i).
+		LabelInt8Ptr("i8", &i8).
+		LabelInt16Ptr("i16", &i16).
+		LabelInt32Ptr("i32", &i32).
+		LabelInt64Ptr("i64", &i64).
+		LabelFloat32Ptr("f32", &f32).
+		LabelFloat64Ptr("f64", &f64).
+		String()
+	require.Equal(t, `test{s="value",q="\"quoted\"",bl="true",u="1",u8="8",u16="16",u32="32",u64="64",i="-1",i8="-8",i16="-16",i32="-32",i64="-64",f32="3.5",f64="6.25"}`, metric)
+
+	metric = Metric("test").
+		LabelStringPtr("s", nil).
+		LabelBoolPtr("bl", nil).
+		LabelIntPtr("i", nil).
+		LabelFloat64Ptr("f64", nil).
+		String()
+	require.Equal(t, "test", metric)
+
+	require.Panics(t, func() {
+		var b Builder
+		b.LabelIntPtr("i", nil)
+	})
+}
@@ -0,0 +1,134 @@
+package vimebu
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+// SetLabel adds a label with a value of type string to the [Builder], replacing the value of the label
+// with the same name if it has already been added, in place. See [Builder.LabelString].
+//
+// If multiple labels with the same name have been added, the first one is replaced, and the others are removed.
+// If the new value is invalid, or if the resulting series exceeds the limits set using [WithMaxLabels] and
+// [WithMaxSeriesLen], the labels are left unchanged.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) SetLabel(name, value string) *Builder {
+	return b.setLabel(name, value, false)
+}
+
+// SetLabelQuote adds a label with a value of type string to the [Builder], replacing the value of the label
+// with the same name if it has already been added, in place. See [Builder.LabelStringQuote].
+// Quotes inside label value will be escaped using [strconv.AppendQuote].
+//
+// If multiple labels with the same name have been added, the first one is replaced, and the others are removed.
+// If the new value is invalid, or if the resulting series exceeds the limits set using [WithMaxLabels] and
+// [WithMaxSeriesLen], the labels are left unchanged.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) SetLabelQuote(name, value string) *Builder {
+	return b.setLabel(name, value, true)
+}
+
+func (b *Builder) setLabel(name, value string, escapeQuotes bool) *Builder {
+	i := b.labelIndex(name)
+	if i < 0 { // Fast path for labels which haven't been added yet.
+		return b.labelString(name, value, escapeQuotes)
+	}
+
+	// The new label is appended at the end of the buffer using the regular validation, without the limits
+	// which are checked against the final buffer below, and is then cut to only keep its value.
+	n := len(b.labels)
+	maxLabels, maxSeriesLen := b.maxLabels, b.maxSeriesLen
+	b.maxLabels, b.maxSeriesLen = 0, 0
+	b.labelString(name, value, escapeQuotes)
+	b.maxLabels, b.maxSeriesLen = maxLabels, maxSeriesLen
+	if len(b.labels) == n { // The new value is invalid, the label is left unchanged.
+		return b
+	}
+	added := b.labels[n]
+	newValue := slices.Clone(b.buf[added.nameEnd+1 : added.end])
+	b.buf = b.buf[:added.start]
+	b.labels = b.labels[:n]
+
+	span := b.labels[i]
+	count, seriesLen := 1, len(b.buf)-(span.end-span.nameEnd-1)+len(newValue)+1 // The closing bracket counts.
+	for j := range b.labels {
+		if j == i {
+			continue
+		}
+		if b.hasLabelName(j, name) { // Removed below.
+			seriesLen -= b.labels[j].end - b.labels[j].start
+			continue
+		}
+		count++
+	}
+	if !b.isWithinSetLimits(name, count, seriesLen) {
+		return b
+	}
+
+	for j := len(b.labels) - 1; j > i; j-- {
+		if b.hasLabelName(j, name) {
+			b.removeLabelAt(j)
+		}
+	}
+	b.spliceLabels(i, span.nameEnd+1, span.end, newValue)
+	b.labels[i].quoted = added.quoted
+	return b
+}
+
+// isWithinSetLimits checks if the series resulting from replacing the value of a label, with count labels
+// and a length of seriesLen, fits in the limits.
+//
+// As the replaced label can't be truncated in place, it is left unchanged unless the policy is [LimitPanic].
+func (b *Builder) isWithinSetLimits(name string, count, seriesLen int) bool {
+	if b.maxLabels > 0 && count > b.maxLabels {
+		if b.limitPolicy == LimitPanic {
+			panic(fmt.Sprintf("vimebu: metric %q, label name %q exceeds set limit of %d labels", b.buf, name, b.maxLabels))
+		}
+		log.Printf("vimebu: metric %q, label name %q exceeds set limit of %d labels - skipping", b.buf, name, b.maxLabels)
+		return false
+	}
+	if b.maxSeriesLen > 0 && seriesLen > b.maxSeriesLen {
+		if b.limitPolicy == LimitPanic {
+			panic(fmt.Sprintf("vimebu: metric %q, label name %q, series len exceeds set limit of %d", b.buf, name, b.maxSeriesLen))
+		}
+		log.Printf("vimebu: metric %q, label name %q, series len exceeds set limit of %d - skipping", b.buf, name, b.maxSeriesLen)
+		return false
+	}
+	return true
+}
+
+// RemoveLabel removes all the labels with the provided name from the [Builder].
+//
+// NoOp if no label with the provided name has been added.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) RemoveLabel(name string) *Builder {
+	if !b.hasFlag(flagHasMetricName) {
+		panic("vimebu: can't remove a label from a Builder with no metric name")
+	}
+	for i := len(b.labels) - 1; i >= 0; i-- {
+		if b.hasLabelName(i, name) {
+			b.removeLabelAt(i)
+		}
+	}
+	return b
+}
+
+// labelIndex returns the index of the first label with the provided name, or -1 if there is none.
+func (b *Builder) labelIndex(name string) int {
+	for i := range b.labels {
+		if b.hasLabelName(i, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasLabelName reports whether the i-th label has the provided name.
+func (b *Builder) hasLabelName(i int, name string) bool {
+	span := b.labels[i]
+	return string(b.buf[span.start+1:span.nameEnd]) == name
+}
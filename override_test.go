@@ -0,0 +1,122 @@
+package vimebu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderSetLabel(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		build    func(b *Builder) *Builder
+		expected string
+	}{
+		{
+			name:     "missing label",
+			build:    func(b *Builder) *Builder { return b.LabelString("method", "GET").SetLabel("status", "200") },
+			expected: `requests_total{method="GET",status="200"}`,
+		},
+		{
+			name: "first label",
+			build: func(b *Builder) *Builder {
+				return b.LabelString("status", "unknown").LabelString("method", "GET").SetLabel("status", "200")
+			},
+			expected: `requests_total{status="200",method="GET"}`,
+		},
+		{
+			name: "middle label",
+			build: func(b *Builder) *Builder {
+				return b.LabelString("method", "GET").LabelString("status", "unknown").LabelInt("code", 0).SetLabelQuote("status", `"ok"`).LabelString("path", "/")
+			},
+			expected: `requests_total{method="GET",status="\"ok\"",code="0",path="/"}`,
+		},
+		{
+			name: "duplicated label",
+			build: func(b *Builder) *Builder {
+				return b.LabelString("status", "a").LabelString("method", "GET").LabelString("status", "b").SetLabel("status", "200")
+			},
+			expected: `requests_total{status="200",method="GET"}`,
+		},
+		{
+			name: "invalid value",
+			build: func(b *Builder) *Builder {
+				return b.LabelString("status", "unknown").LabelString("method", "GET").SetLabel("status", "")
+			},
+			expected: `requests_total{status="unknown",method="GET"}`,
+		},
+		{
+			name: "invalid value with duplicated label",
+			build: func(b *Builder) *Builder {
+				return b.LabelString("status", "a").LabelString("method", "GET").LabelString("status", "b").SetLabel("status", "")
+			},
+			expected: `requests_total{status="a",method="GET",status="b"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			captureLogOutput(func() {
+				b := AcquireBuilder()
+				defer ReleaseBuilder(b)
+				tc.build(b.Metric("requests_total"))
+				require.Equal(t, tc.expected, b.String())
+				// The spans must be kept consistent for further changes.
+				require.Equal(t, tc.expected, NewBuilderPool().Metric("requests_total").Labels(b.appendLabels(nil)).String())
+			})
+		})
+	}
+}
+
+func TestBuilderSetLabelLimits(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("m", WithMaxSeriesLen(20)).
+			LabelString("a", "1").
+			LabelString("b", "2").
+			SetLabel("a", "123456789").
+			String()
+		require.Equal(t, `m{a="1",b="2"}`, metric)
+	})
+	require.Len(t, logLines, 1)
+	require.Contains(t, logLines[0], `label name "a", series len exceeds set limit of 20 - skipping`)
+
+	// The removed duplicates leave room for the new value.
+	logLines = captureLogOutput(func() {
+		metric := Metric("m", WithMaxSeriesLen(20)).
+			LabelString("a", "1").
+			LabelString("b", "2").
+			LabelString("a", "3").
+			SetLabel("a", "12345").
+			LabelString("c", "4"). // Doesn't fit anymore.
+			String()
+		require.Equal(t, `m{a="12345",b="2"}`, metric)
+	})
+	require.Len(t, logLines, 1)
+	require.Contains(t, logLines[0], `label name "c", series len exceeds set limit of 20 - skipping`)
+
+	require.PanicsWithValue(t, `vimebu: metric "m{a=\"1\",b=\"2\"", label name "a", series len exceeds set limit of 20`, func() {
+		Metric("m", WithMaxSeriesLen(20), WithLimitPolicy(LimitPanic)).LabelString("a", "1").LabelString("b", "2").SetLabel("a", "123456789")
+	})
+}
+
+func TestBuilderRemoveLabel(t *testing.T) {
+	metric := Metric("requests_total").
+		LabelString("status", "a").
+		LabelString("method", "GET").
+		LabelString("status", "b").
+		LabelString("path", "/").
+		RemoveLabel("status").
+		RemoveLabel("missing").
+		LabelInt("code", 200).
+		String()
+	require.Equal(t, `requests_total{method="GET",path="/",code="200"}`, metric)
+
+	metric = Metric("requests_total").LabelString("method", "GET").RemoveLabel("method").String()
+	require.Equal(t, "requests_total", metric)
+
+	metric = Metric("requests_total").LabelString("method", "GET").RemoveLabel("method").LabelString("path", "/").String()
+	require.Equal(t, `requests_total{path="/"}`, metric)
+
+	require.Panics(t, func() {
+		var b Builder
+		b.RemoveLabel("method")
+	})
+}
@@ -0,0 +1,55 @@
+package vimebu
+
+// WithEmptyLabelPlaceholder sets the value used instead of skipping the labels with an empty or nil value,
+// such as "unknown" or "none", keeping the same set of labels across the series of a metric.
+//
+// Only applies to label values added using the following methods, and the methods relying on them :
+//
+//   - [Builder.LabelString]
+//   - [Builder.LabelStringQuote]
+//   - [Builder.LabelStringer]
+//   - [Builder.LabelStringerQuote]
+//   - [Builder.LabelError]
+//   - [Builder.LabelErrorQuote]
+//   - [Builder.LabelNamedError]
+//   - [Builder.LabelNamedErrorQuote]
+//   - [Builder.LabelErrorClass]
+//   - [Builder.LabelNamedErrorClass]
+//   - [Builder.LabelAppend]
+//   - [Builder.LabelTextAppender]
+//
+// The placeholders set for specific label names using [WithLabelPlaceholder] take precedence.
+func WithEmptyLabelPlaceholder(placeholder string) BuilderOption {
+	return func(b *Builder) {
+		b.emptyLabelPlaceholder = placeholder
+	}
+}
+
+// WithLabelPlaceholder sets the value used instead of skipping the label with the provided name when its value
+// is empty or nil, see [WithEmptyLabelPlaceholder].
+func WithLabelPlaceholder(name, placeholder string) BuilderOption {
+	return func(b *Builder) {
+		b.labelPlaceholders = append(b.labelPlaceholders, Label{Name: name, Value: placeholder})
+	}
+}
+
+// labelPlaceholder returns the placeholder of the label name, or an empty string if there is none.
+func (b *Builder) labelPlaceholder(name string) string {
+	// Searched backwards, so that the last placeholder set for a name wins.
+	for i := len(b.labelPlaceholders) - 1; i >= 0; i-- {
+		if b.labelPlaceholders[i].Name == name {
+			return b.labelPlaceholders[i].Value
+		}
+	}
+	return b.emptyLabelPlaceholder
+}
+
+// labelNil adds the placeholder of the label name in place of a nil value.
+//
+// NoOp if the label name has no placeholder.
+func (b *Builder) labelNil(name string) *Builder {
+	if len(b.labelPlaceholder(name)) == 0 {
+		return b
+	}
+	return b.LabelStringQuote(name, "")
+}
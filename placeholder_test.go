@@ -0,0 +1,68 @@
+package vimebu
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderOptionsWithEmptyLabelPlaceholder(t *testing.T) {
+	var nilStringer fmt.Stringer
+	logLines := captureLogOutput(func() {
+		metric := Metric("requests_total", WithEmptyLabelPlaceholder("unknown"), WithLabelPlaceholder("error", "none")).
+			LabelString("host", "").
+			LabelStringQuote("path", "").
+			LabelStringer("user", nilStringer).
+			LabelError(nil).
+			LabelNamedErrorClass("reason", nil).
+			LabelString("method", "GET").
+			String()
+		require.Equal(t, `requests_total{host="unknown",path="unknown",user="unknown",error="none",reason="unknown",method="GET"}`, metric)
+	})
+	require.Empty(t, logLines)
+
+	// The same family keeps the same labels whether the values are set or not.
+	metric := Metric("requests_total", WithEmptyLabelPlaceholder("unknown")).
+		LabelString("host", "example.com").
+		LabelError(errors.New("boom")).
+		String()
+	require.Equal(t, `requests_total{host="example.com",error="boom"}`, metric)
+}
+
+func TestBuilderOptionsWithLabelPlaceholder(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("requests_total", WithLabelPlaceholder("host", "none"), WithLabelPlaceholder("host", "unknown")).
+			LabelString("host", "").
+			LabelString("path", "").
+			LabelError(nil).
+			String()
+		require.Equal(t, `requests_total{host="unknown"}`, metric)
+	})
+	require.Len(t, logLines, 1) // The empty path is still skipped, the nil error is silently skipped.
+}
+
+func TestBuilderOptionsWithEmptyLabelPlaceholderAppender(t *testing.T) {
+	logLines := captureLogOutput(func() {
+		metric := Metric("requests_total", WithEmptyLabelPlaceholder("unknown")).
+			LabelAppend("host", appenderValue{}).
+			LabelStringer("user", appenderValue{}).
+			LabelTextAppender("ip", netip.Addr{}).
+			LabelAppend("method", appenderValue{"GET"}).
+			String()
+		require.Equal(t, `requests_total{host="unknown",user="unknown",ip="unknown",method="GET"}`, metric)
+	})
+	require.Empty(t, logLines)
+
+	// Without placeholder, the empty appended values are still skipped.
+	logLines = captureLogOutput(func() {
+		metric := Metric("requests_total").
+			LabelAppend("host", appenderValue{}).
+			LabelAppend("method", appenderValue{"GET"}).
+			String()
+		require.Equal(t, `requests_total{method="GET"}`, metric)
+	})
+	require.Len(t, logLines, 1)
+}
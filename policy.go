@@ -0,0 +1,211 @@
+package vimebu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Policy describes per-metric restrictions applied to the [Builder] instances acquired from a [BuilderPool],
+// see [BuilderPool.SetPolicy] and [BuilderPool.WatchPolicyFile].
+type Policy struct {
+	// Metrics holds the policy of each metric, by metric name.
+	//
+	// The policy is resolved from the metric name passed to [Builder.Metric], before the relabeling rules
+	// (see [WithRelabelConfigs]) are applied: renaming a metric doesn't change the policy applied to it.
+	Metrics map[string]MetricPolicy `json:"metrics"`
+}
+
+// MetricPolicy describes the restrictions applied to a metric.
+type MetricPolicy struct {
+	// Disabled drops the metric: [Builder.String] returns an empty string, and the helpers creating
+	// VictoriaMetrics metrics return no-op metrics.
+	Disabled bool `json:"disabled,omitempty"`
+	// AllowedLabels lists the label names allowed on the metric, other labels are skipped.
+	// An empty list allows all label names.
+	//
+	// It applies in addition to the allowlist set using [WithLabelAllowlist], a label must be allowed by both.
+	AllowedLabels []string `json:"allowed_labels,omitempty"`
+	// LabelNameMaxLen overrides the max len set using [WithLabelNameMaxLen] if greater than zero.
+	LabelNameMaxLen int `json:"label_name_max_len,omitempty"`
+	// LabelValueMaxLen overrides the max len set using [WithLabelValueMaxLen] if greater than zero.
+	LabelValueMaxLen int `json:"label_value_max_len,omitempty"`
+	// MaxSeries caps the number of distinct series built for the metric, the series exceeding it are dropped.
+	// Zero means no limit.
+	MaxSeries int `json:"max_series,omitempty"`
+}
+
+// ParsePolicy parses a JSON [Policy], and validates it.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("vimebu: can't parse policy: %w", err)
+	}
+	for name, mp := range policy.Metrics {
+		if len(name) == 0 {
+			return nil, fmt.Errorf("vimebu: invalid policy, empty metric name")
+		}
+		if mp.LabelNameMaxLen < 0 || mp.LabelValueMaxLen < 0 || mp.MaxSeries < 0 {
+			return nil, fmt.Errorf("vimebu: invalid policy for metric %q, negative limit", name)
+		}
+	}
+	return &policy, nil
+}
+
+// policyState is the compiled form of a [Policy], holding the series seen for the capped metrics.
+type policyState struct {
+	metrics map[string]*metricPolicy
+}
+
+type metricPolicy struct {
+	MetricPolicy
+
+	allowedLabels map[string]struct{}
+
+	mu         sync.Mutex
+	series     map[string]struct{}
+	capReached bool
+}
+
+func newPolicyState(policy *Policy) *policyState {
+	state := &policyState{metrics: make(map[string]*metricPolicy, len(policy.Metrics))}
+	for name, mp := range policy.Metrics {
+		compiled := &metricPolicy{MetricPolicy: mp}
+		if len(mp.AllowedLabels) > 0 {
+			compiled.allowedLabels = make(map[string]struct{}, len(mp.AllowedLabels))
+			for _, label := range mp.AllowedLabels {
+				compiled.allowedLabels[label] = struct{}{}
+			}
+		}
+		if mp.MaxSeries > 0 {
+			compiled.series = make(map[string]struct{})
+		}
+		state.metrics[name] = compiled
+	}
+	return state
+}
+
+// allowsLabel reports whether the label name is allowed on the metric.
+func (mp *metricPolicy) allowsLabel(name string) bool {
+	if mp.allowedLabels == nil {
+		return true
+	}
+	_, ok := mp.allowedLabels[name]
+	return ok
+}
+
+// admitSeries reports whether the series can be built without exceeding the cap of the metric.
+func (mp *metricPolicy) admitSeries(series string) bool {
+	if mp.MaxSeries == 0 {
+		return true
+	}
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if _, ok := mp.series[series]; ok {
+		return true
+	}
+	if len(mp.series) >= mp.MaxSeries {
+		if !mp.capReached { // Only report the first dropped series, to avoid flooding the logs.
+			mp.capReached = true
+			log.Printf("vimebu: metric %q, series cap of %d reached - dropping new series", series, mp.MaxSeries)
+		}
+		return false
+	}
+	mp.series[series] = struct{}{}
+	return true
+}
+
+// SetPolicy atomically replaces the [Policy] applied to the [Builder] instances acquired from the pool.
+// The [Builder] instances on which [Builder.Metric] has already been called keep using the previous policy.
+//
+// The series counted against the [MetricPolicy.MaxSeries] caps are reset.
+//
+// Passing nil removes the policy.
+//
+// Use [DefaultBuilderPool] to apply a policy to the metrics built by [Metric] and the instrumentation helpers.
+func (p *BuilderPool) SetPolicy(policy *Policy) {
+	if policy == nil {
+		p.policy.Store(nil)
+		return
+	}
+	p.policy.Store(newPolicyState(policy))
+}
+
+// WatchPolicyFile loads the [Policy] from the JSON file at path, and applies it to the pool using [BuilderPool.SetPolicy].
+// The file is then polled at the provided interval until ctx is canceled, and the policy is reloaded when its content changes.
+//
+// If the file can't be read or parsed while reloading, a log line containing the reason will be written to [os.Stderr],
+// and the current policy will be kept.
+//
+// Returns an error if the policy can't be loaded initially.
+//
+// Panics if interval isn't positive.
+func (p *BuilderPool) WatchPolicyFile(ctx context.Context, path string, interval time.Duration) error {
+	if interval <= 0 {
+		panic("vimebu: BuilderPool.WatchPolicyFile has been passed a non-positive interval")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("vimebu: can't read policy file: %w", err)
+	}
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return err
+	}
+	p.SetPolicy(policy)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := os.ReadFile(path)
+			if err != nil {
+				log.Printf("vimebu: policy file %q, can't reload: %v - keeping the current policy", path, err)
+				continue
+			}
+			if bytes.Equal(current, data) {
+				continue
+			}
+			data = current
+			policy, err := ParsePolicy(current)
+			if err != nil {
+				log.Printf("vimebu: policy file %q, can't reload: %v - keeping the current policy", path, err)
+				continue
+			}
+			p.SetPolicy(policy)
+		}
+	}()
+	return nil
+}
+
+// applyPolicy resolves the policy of the metric from the policy of the pool, if any.
+func (b *Builder) applyPolicy(name string) {
+	state := b.pool.policy.Load()
+	if state == nil {
+		return
+	}
+	mp, ok := state.metrics[name]
+	if !ok {
+		return
+	}
+	if mp.Disabled {
+		b.setFlag(flagDropped)
+	}
+	if mp.LabelNameMaxLen > 0 {
+		b.labelNameMaxLen = mp.LabelNameMaxLen
+	}
+	if mp.LabelValueMaxLen > 0 {
+		b.labelValueMaxLen = mp.LabelValueMaxLen
+	}
+	b.policy = mp
+}
@@ -0,0 +1,138 @@
+package vimebu
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`{"metrics": {
+		"noisy_total": {"disabled": true},
+		"requests_total": {"allowed_labels": ["method"], "label_value_max_len": 8, "max_series": 2}
+	}}`))
+	require.NoError(t, err)
+	require.True(t, policy.Metrics["noisy_total"].Disabled)
+	require.Equal(t, []string{"method"}, policy.Metrics["requests_total"].AllowedLabels)
+
+	for _, data := range []string{
+		`[]`,
+		`{"metrics": {"": {}}}`,
+		`{"metrics": {"requests_total": {"max_series": -1}}}`,
+	} {
+		_, err := ParsePolicy([]byte(data))
+		require.Error(t, err, data)
+	}
+}
+
+func TestBuilderPoolSetPolicy(t *testing.T) {
+	pool := NewBuilderPool()
+	pool.SetPolicy(&Policy{Metrics: map[string]MetricPolicy{
+		"noisy_total":    {Disabled: true},
+		"requests_total": {AllowedLabels: []string{"method", "path"}, LabelValueMaxLen: 8, MaxSeries: 2},
+	}})
+	set := metrics.NewSet()
+
+	pool.Metric("noisy_total").LabelString("a", "b").GetOrCreateCounterInSet(set).Inc()
+
+	logLines := captureLogOutput(func() {
+		for _, method := range []string{"GET", "GET", "POST", "PUT", "DELETE"} {
+			pool.Metric("requests_total").
+				LabelString("method", method).
+				LabelString("path", "/a/very/long/path").
+				LabelString("user", "gopher").
+				GetOrCreateCounterInSet(set).
+				Inc()
+		}
+	})
+	require.Len(t, logLines, 11)                                // Two skipped labels per call, and the cap being reached once.
+	require.Contains(t, logLines[8], "series cap of 2 reached") // Reached by the PUT series.
+
+	require.Equal(t, "requests_total{method=\"GET\"} 2\nrequests_total{method=\"POST\"} 1\n", writeSet(set))
+
+	// Removing the policy.
+	pool.SetPolicy(nil)
+	require.Equal(t, `noisy_total{a="b"}`, pool.Metric("noisy_total").LabelString("a", "b").String())
+}
+
+func TestBuilderPoolWatchPolicyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	pool := NewBuilderPool()
+
+	require.Error(t, pool.WatchPolicyFile(t.Context(), path, time.Millisecond))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"metrics": {"noisy_total": {"disabled": true}}}`), 0o600))
+	require.NoError(t, pool.WatchPolicyFile(t.Context(), path, time.Millisecond))
+	require.Empty(t, pool.Metric("noisy_total").String())
+
+	// The reload happens in another goroutine, the log output must be safe for concurrent use.
+	var output syncBuffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+	require.NoError(t, os.WriteFile(path, []byte(`{"metrics": `), 0o600))
+	require.Eventually(t, func() bool {
+		return strings.Contains(output.String(), "can't reload")
+	}, time.Second, time.Millisecond)
+	require.Empty(t, pool.Metric("noisy_total").String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"metrics": {"other_total": {"disabled": true}}}`), 0o600))
+	require.Eventually(t, func() bool {
+		return pool.Metric("noisy_total").String() == "noisy_total"
+	}, time.Second, time.Millisecond)
+}
+
+// syncBuffer is a [bytes.Buffer] safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDefaultBuilderPoolPolicy(t *testing.T) {
+	previous := DefaultBuilderPool()
+	t.Cleanup(func() { SetDefaultBuilderPool(previous) })
+
+	pool := NewBuilderPool(WithLabelRedaction(RedactEmails))
+	SetDefaultBuilderPool(pool)
+	require.Same(t, pool, DefaultBuilderPool())
+	captureLogOutput(func() {
+		require.Equal(t, `logins_total{user="[redacted]"}`, Metric("logins_total").LabelString("user", "gopher@example.com").String())
+	})
+
+	// The integrations build their metrics from the default pool, so its policy applies to them at runtime.
+	pool.SetPolicy(&Policy{Metrics: map[string]MetricPolicy{"http_server_response_size_bytes": {Disabled: true}}})
+	set := metrics.NewSet()
+	handler := InstrumentHTTPHandler(http.NotFoundHandler(), WithHTTPServerSet(set))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	out := writeSet(set)
+	require.Contains(t, out, `http_server_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.NotContains(t, out, "http_server_response_size_bytes")
+
+	pool.SetPolicy(nil)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, writeSet(set), `http_server_response_size_bytes_count{method="GET",route="unmatched",status="404"} 1`)
+
+	require.Panics(t, func() { SetDefaultBuilderPool(nil) })
+}
@@ -0,0 +1,100 @@
+package vimebu
+
+import (
+	"log"
+	"regexp"
+)
+
+const defaultRedactionReplacement string = "[redacted]"
+
+// RedactionAction is the action taken when a [RedactionRule] matches a label value.
+type RedactionAction uint8
+
+const (
+	// RedactionScrub replaces the matches of the rule with its replacement.
+	RedactionScrub RedactionAction = iota
+	// RedactionDrop skips the whole label.
+	RedactionDrop
+)
+
+// RedactionRule detects sensitive data (PII, secrets...) in label values, see [WithLabelRedaction].
+//
+// Rules are plain values, a builtin rule can be copied to change its action :
+//
+//	rule := vimebu.RedactEmails
+//	rule.Action = vimebu.RedactionDrop
+type RedactionRule struct {
+	// Name identifies the rule in the log lines reporting redacted labels.
+	Name string
+	// Pattern matches the sensitive data.
+	Pattern *regexp.Regexp
+	// Action is the action taken when Pattern matches a label value.
+	Action RedactionAction
+	// Replacement replaces the matches of Pattern when Action is [RedactionScrub].
+	// Defaults to "[redacted]".
+	Replacement string
+}
+
+// Builtin redaction rules, scrubbing the sensitive data they detect.
+var (
+	// RedactEmails detects email addresses.
+	RedactEmails = RedactionRule{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+	// RedactTokens detects bearer tokens and JWTs.
+	RedactTokens = RedactionRule{
+		Name:    "token",
+		Pattern: regexp.MustCompile(`(?i:bearer)\s+[A-Za-z0-9\-._~+/]+=*|eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	}
+	// RedactCreditCards detects runs of 13 to 19 digits, optionally separated by spaces or dashes.
+	RedactCreditCards = RedactionRule{
+		Name:    "credit_card",
+		Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	}
+	// RedactIPs detects IPv4 addresses, and IPv6 addresses in their full or compressed forms.
+	RedactIPs = RedactionRule{
+		Name:    "ip",
+		Pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|\b(?:[0-9A-Fa-f]{1,4}:){7}[0-9A-Fa-f]{1,4}\b|(?:\b[0-9A-Fa-f]{1,4}:){1,6}:(?:[0-9A-Fa-f]{1,4}\b(?::[0-9A-Fa-f]{1,4}\b)*)?|::(?:[0-9A-Fa-f]{1,4}\b(?::[0-9A-Fa-f]{1,4}\b)*)`),
+	}
+)
+
+// WithLabelRedaction registers redaction rules applied to all the label values added to the [Builder]
+// instances acquired from the pool, after the normalizers registered using [WithLabelNormalizers].
+//
+// Applies to label values added using [Builder.LabelString] and the methods relying on it,
+// such as [Builder.LabelStringQuote], [Builder.LabelStringer] or [Builder.LabelError], and to the values
+// written by [Builder.LabelAppend], [Builder.LabelTextAppender] and the methods relying on them,
+// which are then converted to a string. [Builder.LabelHashed] applies them to the value before hashing it.
+// Doesn't apply to numeric label values.
+//
+// When a rule matches a label value, a log line containing the label name and the rule name (but not the
+// value) will be written to [os.Stderr], and the value will be scrubbed or the label skipped depending
+// on the action of the rule.
+func WithLabelRedaction(rules ...RedactionRule) BuilderPoolOption {
+	return func(p *BuilderPool) {
+		p.redactionRules = append(p.redactionRules, rules...)
+	}
+}
+
+// redactLabelValue applies the redaction rules of the pool to value.
+//
+// Returns false if the label must be skipped.
+func (b *Builder) redactLabelValue(name, value string) (string, bool) {
+	for _, rule := range b.pool.redactionRules {
+		if !rule.Pattern.MatchString(value) {
+			continue
+		}
+		if rule.Action == RedactionDrop {
+			log.Printf("vimebu: metric %q, label name %q, label value matched redaction rule %q - skipping", b.buf, name, rule.Name)
+			return "", false
+		}
+		log.Printf("vimebu: metric %q, label name %q, label value matched redaction rule %q - scrubbing", b.buf, name, rule.Name)
+		replacement := rule.Replacement
+		if len(replacement) == 0 {
+			replacement = defaultRedactionReplacement
+		}
+		value = rule.Pattern.ReplaceAllLiteralString(value, replacement)
+	}
+	return value, true
+}
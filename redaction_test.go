@@ -0,0 +1,93 @@
+package vimebu
+
+import (
+	"errors"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactionRules(t *testing.T) {
+	for _, tc := range []struct {
+		rule    RedactionRule
+		matches []string
+		misses  []string
+	}{
+		{RedactEmails, []string{"gopher@example.com", "user: a.b+c@mail.co.uk"}, []string{"gopher@", "@example"}},
+		{RedactTokens, []string{"Bearer abc.def-ghi", "token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig"}, []string{"bearer", "eyJ"}},
+		{RedactCreditCards, []string{"4111111111111111", "4111 1111 1111 1111", "4111-1111-1111-1111"}, []string{"123456789012", "order 42"}},
+		{RedactIPs, []string{"10.0.0.1", "dial tcp 192.168.1.1:443", "2001:db8::1", "fe80::1ff:fe23:4567:890a", "::1", "2001:0db8:0000:0000:0000:ff00:0042:8329"}, []string{"v1.2.3", "12:30:45"}},
+	} {
+		for _, value := range tc.matches {
+			require.True(t, tc.rule.Pattern.MatchString(value), "%s should match %q", tc.rule.Name, value)
+		}
+		for _, value := range tc.misses {
+			require.False(t, tc.rule.Pattern.MatchString(value), "%s should not match %q", tc.rule.Name, value)
+		}
+	}
+}
+
+func TestBuilderPoolLabelRedaction(t *testing.T) {
+	dropTokens := RedactTokens
+	dropTokens.Action = RedactionDrop
+	pool := NewBuilderPool(
+		WithLabelNormalizers("user", NormalizeTrimSpace),
+		WithLabelRedaction(RedactEmails, RedactIPs, dropTokens, RedactionRule{
+			Name:        "ticket",
+			Pattern:     regexp.MustCompile(`TICKET-\d+`),
+			Replacement: "TICKET-*",
+		}),
+	)
+
+	logLines := captureLogOutput(func() {
+		metric := pool.Metric("errors_total").
+			LabelString("user", " gopher@example.com ").
+			LabelError(errors.New("dial tcp 10.0.0.1:443: connection refused")).
+			LabelString("auth", "Bearer abcdef").
+			LabelString("ticket", "TICKET-1234").
+			LabelString("safe", "nothing to see").
+			String()
+		require.Equal(t, `errors_total{user="[redacted]",error="dial tcp [redacted]:443: connection refused",ticket="TICKET-*",safe="nothing to see"}`, metric)
+	})
+
+	require.Len(t, logLines, 4)
+	for _, line := range logLines {
+		require.NotContains(t, line, "gopher@example.com")
+		require.NotContains(t, line, "abcdef")
+	}
+	require.True(t, strings.HasSuffix(logLines[2], "- skipping"))
+}
+
+// emailAddress implements both [fmt.Stringer] and [LabelAppender].
+type emailAddress string
+
+func (e emailAddress) String() string { return string(e) }
+
+func (e emailAddress) AppendLabelValue(dst []byte) []byte { return append(dst, e...) }
+
+func TestBuilderPoolLabelRedactionAppender(t *testing.T) {
+	pool := NewBuilderPool(
+		WithLabelNormalizers("domain", NormalizeLower),
+		WithLabelRedaction(RedactEmails),
+	)
+
+	logLines := captureLogOutput(func() {
+		metric := pool.Metric("logins_total").
+			LabelStringer("user", emailAddress("gopher@example.com")).
+			LabelAppend("owner", emailAddress("admin@example.com")).
+			LabelAppend("domain", emailAddress("Example.COM")).
+			LabelTextAppender("ip", netip.MustParseAddr("10.0.0.1")).
+			String()
+		require.Equal(t, `logins_total{user="[redacted]",owner="[redacted]",domain="example.com",ip="10.0.0.1"}`, metric)
+	})
+	require.Len(t, logLines, 2)
+
+	// Without normalizers nor redaction rules, the values are appended directly.
+	metric := NewBuilderPool().Metric("logins_total").
+		LabelStringer("user", emailAddress("gopher@example.com")).
+		String()
+	require.Equal(t, `logins_total{user="gopher@example.com"}`, metric)
+}
@@ -0,0 +1,291 @@
+package vimebu
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MetricNameLabel is the name of the label holding the metric name in [RelabelConfig] rules.
+const MetricNameLabel string = "__name__"
+
+const (
+	defaultRelabelSeparator   string = ";"
+	defaultRelabelRegex       string = "(.*)"
+	defaultRelabelReplacement string = "$1"
+)
+
+// RelabelAction is the action performed by a [RelabelConfig].
+type RelabelAction string
+
+const (
+	// RelabelReplace sets the target label to the replacement, expanded with the regex capture groups,
+	// if the regex matches the concatenated source label values. The target label is removed if the
+	// expanded replacement is empty.
+	RelabelReplace RelabelAction = "replace"
+	// RelabelKeep drops the metric if the regex doesn't match the concatenated source label values.
+	RelabelKeep RelabelAction = "keep"
+	// RelabelDrop drops the metric if the regex matches the concatenated source label values.
+	RelabelDrop RelabelAction = "drop"
+	// RelabelHashMod sets the target label to the modulus of the hash of the concatenated source label values.
+	RelabelHashMod RelabelAction = "hashmod"
+	// RelabelLabelDrop removes the labels whose name matches the regex.
+	RelabelLabelDrop RelabelAction = "labeldrop"
+	// RelabelLabelKeep removes the labels whose name doesn't match the regex.
+	RelabelLabelKeep RelabelAction = "labelkeep"
+)
+
+// RelabelConfig is a Prometheus-style relabeling rule, applied to the labels accumulated in the [Builder] instances
+// acquired from a [BuilderPool] configured with [WithRelabelConfigs], when building the metric.
+//
+// The metric name can be read and rewritten using the "__name__" label (see [MetricNameLabel]),
+// it is never removed by the [RelabelLabelDrop] and [RelabelLabelKeep] actions.
+type RelabelConfig struct {
+	// SourceLabels are the labels whose values are concatenated using the separator,
+	// and matched against the regex. Missing labels have an empty value.
+	SourceLabels []string `json:"source_labels,omitempty"`
+	// Separator is placed between the concatenated source label values.
+	//
+	// Defaults to ";" if nil.
+	Separator *string `json:"separator,omitempty"`
+	// TargetLabel is the label written by the [RelabelReplace] and [RelabelHashMod] actions.
+	// It may reference the regex capture groups with the [RelabelReplace] action.
+	TargetLabel string `json:"target_label,omitempty"`
+	// Regex is the regular expression matched against the concatenated source label values,
+	// or against the label names with the [RelabelLabelDrop] and [RelabelLabelKeep] actions.
+	// It is anchored at both ends.
+	//
+	// Defaults to "(.*)" if empty.
+	Regex string `json:"regex,omitempty"`
+	// Modulus is the modulus applied to the hash by the [RelabelHashMod] action.
+	Modulus uint64 `json:"modulus,omitempty"`
+	// Replacement is the value written to the target label by the [RelabelReplace] action,
+	// it may reference the regex capture groups (e.g. "$1" or "${name}").
+	//
+	// Defaults to "$1" if nil.
+	Replacement *string `json:"replacement,omitempty"`
+	// Action is the action performed by the rule.
+	//
+	// Defaults to [RelabelReplace] if empty.
+	Action RelabelAction `json:"action,omitempty"`
+}
+
+// ParseRelabelConfigs parses a JSON array of [RelabelConfig], and validates them.
+func ParseRelabelConfigs(data []byte) ([]RelabelConfig, error) {
+	var configs []RelabelConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("vimebu: can't parse relabel configs: %w", err)
+	}
+	for i, cfg := range configs {
+		if _, err := compileRelabelConfig(cfg); err != nil {
+			return nil, fmt.Errorf("vimebu: invalid relabel config #%d: %w", i, err)
+		}
+	}
+	return configs, nil
+}
+
+// WithRelabelConfigs registers relabeling rules applied, in order, to the labels of the [Builder] instances
+// acquired from the pool, when building the metric.
+//
+// If a rule drops the metric, [Builder.String] returns an empty string, and the helpers creating
+// VictoriaMetrics metrics return no-op metrics, which are never exposed.
+//
+// Values of the labels written by the rules are quoted using [strconv.AppendQuote] if needed.
+//
+// Panics if a rule is invalid, see [ParseRelabelConfigs] to validate rules loaded from a file.
+func WithRelabelConfigs(configs ...RelabelConfig) BuilderPoolOption {
+	return func(p *BuilderPool) {
+		for i, cfg := range configs {
+			rule, err := compileRelabelConfig(cfg)
+			if err != nil {
+				panic(fmt.Sprintf("vimebu: WithRelabelConfigs has been passed an invalid relabel config #%d: %v", i, err))
+			}
+			p.relabelRules = append(p.relabelRules, rule)
+		}
+	}
+}
+
+type relabelRule struct {
+	RelabelConfig
+
+	regex       *regexp.Regexp
+	separator   string
+	replacement string
+}
+
+func compileRelabelConfig(cfg RelabelConfig) (relabelRule, error) {
+	rule := relabelRule{
+		RelabelConfig: cfg,
+		separator:     defaultRelabelSeparator,
+		replacement:   defaultRelabelReplacement,
+	}
+	if cfg.Separator != nil {
+		rule.separator = *cfg.Separator
+	}
+	if cfg.Replacement != nil {
+		rule.replacement = *cfg.Replacement
+	}
+	if len(rule.Action) == 0 {
+		rule.Action = RelabelReplace
+	}
+	expr := cfg.Regex
+	if len(expr) == 0 {
+		expr = defaultRelabelRegex
+	}
+	regex, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return rule, err
+	}
+	rule.regex = regex
+
+	switch rule.Action {
+	case RelabelReplace:
+		if len(cfg.TargetLabel) == 0 {
+			return rule, fmt.Errorf("action %q requires a target label", rule.Action)
+		}
+	case RelabelHashMod:
+		if len(cfg.TargetLabel) == 0 {
+			return rule, fmt.Errorf("action %q requires a target label", rule.Action)
+		}
+		if cfg.Modulus == 0 {
+			return rule, fmt.Errorf("action %q requires a non-zero modulus", rule.Action)
+		}
+	case RelabelKeep, RelabelDrop, RelabelLabelDrop, RelabelLabelKeep:
+	default:
+		return rule, fmt.Errorf("unknown action %q", rule.Action)
+	}
+	return rule, nil
+}
+
+// relabel applies the relabeling rules to labels, and reports whether the metric is kept.
+func relabel(rules []relabelRule, labels Labels) (Labels, bool) {
+	for _, rule := range rules {
+		switch rule.Action {
+		case RelabelReplace:
+			value := rule.sourceValue(labels)
+			match := rule.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			target := string(rule.regex.ExpandString(nil, rule.TargetLabel, value, match))
+			if len(target) == 0 {
+				continue
+			}
+			labels = labels.set(target, string(rule.regex.ExpandString(nil, rule.replacement, value, match)))
+		case RelabelKeep:
+			if !rule.regex.MatchString(rule.sourceValue(labels)) {
+				return labels, false
+			}
+		case RelabelDrop:
+			if rule.regex.MatchString(rule.sourceValue(labels)) {
+				return labels, false
+			}
+		case RelabelHashMod:
+			sum := md5.Sum([]byte(rule.sourceValue(labels)))
+			mod := binary.BigEndian.Uint64(sum[8:]) % rule.Modulus
+			labels = labels.set(rule.TargetLabel, strconv.FormatUint(mod, base10))
+		case RelabelLabelDrop, RelabelLabelKeep:
+			keep := rule.Action == RelabelLabelKeep
+			n := 0
+			for _, label := range labels {
+				if label.Name == MetricNameLabel || rule.regex.MatchString(label.Name) == keep {
+					labels[n] = label
+					n++
+				}
+			}
+			labels = labels[:n]
+		}
+	}
+	return labels, true
+}
+
+func (r relabelRule) sourceValue(labels Labels) string {
+	if len(r.SourceLabels) == 1 { // Fast path avoiding the concatenation.
+		value, _ := labels.Get(r.SourceLabels[0])
+		return value
+	}
+	var sb strings.Builder
+	for i, name := range r.SourceLabels {
+		if i > 0 {
+			sb.WriteString(r.separator)
+		}
+		value, _ := labels.Get(name)
+		sb.WriteString(value)
+	}
+	return sb.String()
+}
+
+// set sets the value of the label with the provided name, removing it if value is empty.
+func (l Labels) set(name, value string) Labels {
+	for i, label := range l {
+		if label.Name != name {
+			continue
+		}
+		if len(value) == 0 {
+			return append(l[:i], l[i+1:]...)
+		}
+		l[i].Value = value
+		return l
+	}
+	if len(value) == 0 {
+		return l
+	}
+	return append(l, Label{Name: name, Value: value})
+}
+
+// relabel applies the relabeling rules of the pool to the accumulated labels, and rebuilds the buffer.
+//
+// Returns false if the metric has been dropped.
+func (b *Builder) relabel() bool {
+	nameEnd := b.metricNameEnd()
+	labels := make(Labels, 0, len(b.labels)+1)
+	labels = append(labels, Label{Name: MetricNameLabel, Value: string(b.buf[:nameEnd])})
+	labels = b.appendLabels(labels)
+
+	labels, ok := relabel(b.pool.relabelRules, labels)
+	if !ok {
+		return false
+	}
+	name, _ := labels.Get(MetricNameLabel)
+	if len(name) == 0 {
+		log.Printf("vimebu: metric %q, relabeling removed the metric name - dropping", b.buf[:nameEnd])
+		return false
+	}
+
+	b.buf = append(b.buf[:0], name...)
+	b.labels = b.labels[:0]
+	b.flags &^= flagHasLabel
+	for _, label := range labels {
+		if label.Name == MetricNameLabel {
+			continue
+		}
+		value := label.Value
+		escape := strings.ContainsAny(value, "\"\\\n")
+		b.addLabel(label.Name, func(dst []byte) []byte {
+			if !escape {
+				return append(dst, value...)
+			}
+			return strconv.AppendQuote(dst, value)
+		}, !escape)
+	}
+	return true
+}
+
+// appendLabels appends the labels accumulated in the buffer to dst, with their unquoted values.
+func (b *Builder) appendLabels(dst Labels) Labels {
+	for _, span := range b.labels {
+		value := string(b.buf[span.nameEnd+1 : span.end])
+		if span.quoted {
+			value, _ = strconv.Unquote(value)
+		} else {
+			value = value[1 : len(value)-1]
+		}
+		dst = append(dst, Label{Name: string(b.buf[span.start+1 : span.nameEnd]), Value: value})
+	}
+	return dst
+}
@@ -0,0 +1,92 @@
+package vimebu
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRelabelConfigs(t *testing.T) {
+	configs, err := ParseRelabelConfigs([]byte(`[
+		{"source_labels": ["path"], "regex": "/users/.*", "target_label": "path", "replacement": "/users/:id"},
+		{"action": "labeldrop", "regex": "trace_id"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	require.Equal(t, RelabelLabelDrop, configs[1].Action)
+	require.Equal(t, "/users/:id", *configs[0].Replacement)
+
+	for _, data := range []string{
+		`{}`,
+		`[{"action": "unknown"}]`,
+		`[{"action": "replace"}]`,
+		`[{"action": "hashmod", "target_label": "shard"}]`,
+		`[{"action": "keep", "regex": "("}]`,
+	} {
+		_, err := ParseRelabelConfigs([]byte(data))
+		require.Error(t, err, data)
+	}
+
+	require.Panics(t, func() {
+		NewBuilderPool(WithRelabelConfigs(RelabelConfig{Action: RelabelHashMod}))
+	})
+}
+
+func TestBuilderPoolRelabelConfigs(t *testing.T) {
+	configs, err := ParseRelabelConfigs([]byte(`[
+		{"action": "drop", "source_labels": ["__name__", "env"], "regex": "debug_.*;prod"},
+		{"action": "keep", "source_labels": ["__name__"], "regex": "(http|debug)_.*"},
+		{"source_labels": ["path"], "regex": "/users/[0-9]+", "target_label": "path", "replacement": "/users/:id"},
+		{"source_labels": ["method", "path"], "separator": " ", "target_label": "route"},
+		{"source_labels": ["__name__"], "regex": "http_(.*)", "target_label": "__name__", "replacement": "api_$1"},
+		{"action": "hashmod", "source_labels": ["user"], "target_label": "shard", "modulus": 8},
+		{"action": "labeldrop", "regex": "user|trace_.*"},
+		{"source_labels": ["missing"], "target_label": "env"}
+	]`))
+	require.NoError(t, err)
+	pool := NewBuilderPool(WithRelabelConfigs(configs...))
+
+	sum := md5.Sum([]byte("gopher"))
+	shard := strconv.FormatUint(binary.BigEndian.Uint64(sum[8:])%8, 10)
+
+	metric := pool.Metric("http_requests_total").
+		LabelString("method", "GET").
+		LabelStringQuote("path", "/users/42").
+		LabelString("user", "gopher").
+		LabelString("trace_id", "abc").
+		LabelString("env", "prod").
+		String()
+	require.Equal(t, `api_requests_total{method="GET",path="/users/:id",route="GET /users/:id",shard="`+shard+`"}`, metric)
+
+	// Dropped by the keep rule.
+	require.Empty(t, pool.Metric("sql_queries_total").LabelString("env", "prod").String())
+	// Dropped by the drop rule.
+	require.Empty(t, pool.Metric("debug_total").LabelString("env", "prod").String())
+	require.NotEmpty(t, pool.Metric("debug_total").LabelString("env", "dev").String())
+
+	// Label values requiring escaping.
+	pool = NewBuilderPool(WithRelabelConfigs(RelabelConfig{SourceLabels: []string{"msg"}, TargetLabel: "copy"}))
+	require.Equal(t, `debug_total{msg="say \"hi\"",copy="say \"hi\""}`, pool.Metric("debug_total").LabelStringQuote("msg", `say "hi"`).String())
+}
+
+func TestBuilderPoolRelabelConfigsDroppedMetrics(t *testing.T) {
+	pool := NewBuilderPool(WithRelabelConfigs(RelabelConfig{
+		Action:       RelabelDrop,
+		SourceLabels: []string{"noisy"},
+		Regex:        "true",
+	}))
+	set := metrics.NewSet()
+
+	pool.Metric("events_total").LabelBool("noisy", true).GetOrCreateCounterInSet(set).Inc()
+	pool.Metric("events_total").LabelBool("noisy", false).GetOrCreateCounterInSet(set).Inc()
+	pool.Metric("event_duration_seconds").LabelBool("noisy", true).GetOrCreateHistogramInSet(set).Update(1)
+	pool.Metric("event_value").LabelBool("noisy", true).GetOrCreateGaugeInSet(set, nil).Set(1)
+	pool.Metric("event_size").LabelBool("noisy", true).GetOrCreateSummaryInSet(set).Update(1)
+
+	out := writeSet(set)
+	require.Equal(t, "events_total{noisy=\"false\"} 1\n", out)
+}
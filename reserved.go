@@ -0,0 +1,104 @@
+package vimebu
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+const reservedLabelRenamePrefix string = "exported_"
+
+var (
+	// histogramReservedLabels are the label names added by VictoriaMetrics to the buckets of histograms.
+	histogramReservedLabels = []string{"le", "vmrange"}
+	// summaryReservedLabels are the label names added by VictoriaMetrics to the quantiles of summaries.
+	summaryReservedLabels = []string{"quantile"}
+)
+
+// ReservedLabelPolicy defines how a [Builder] handles labels whose name is reserved by the type of the
+// created VictoriaMetrics metric: "le" and "vmrange" for histograms, "quantile" for summaries.
+type ReservedLabelPolicy uint8
+
+const (
+	// ReservedLabelRename renames the reserved labels by prefixing them with "exported_".
+	// The reserved labels are removed instead if a label with the prefixed name already exists, or if the
+	// prefixed name makes the series exceed the limit set using [WithMaxSeriesLen].
+	ReservedLabelRename ReservedLabelPolicy = iota
+	// ReservedLabelDrop removes the reserved labels.
+	ReservedLabelDrop
+	// ReservedLabelPanic panics if a reserved label has been added.
+	ReservedLabelPanic
+)
+
+// WithReservedLabelPolicy sets how the labels whose name is reserved by the type of the created VictoriaMetrics metric
+// are handled by the helpers creating histograms and summaries, such as [Builder.GetOrCreateHistogram] or
+// [Builder.GetOrCreateSummary].
+//
+// Defaults to [ReservedLabelRename].
+func WithReservedLabelPolicy(policy ReservedLabelPolicy) BuilderOption {
+	return func(b *Builder) {
+		b.reservedLabelPolicy = policy
+	}
+}
+
+// WithReservedLabelReporter sets a function called with the metric and label names of each reserved label
+// renamed or removed, see [WithReservedLabelPolicy].
+//
+// Defaults to writing a log line to [os.Stderr].
+func WithReservedLabelReporter(report func(metric, label string)) BuilderOption {
+	return func(b *Builder) {
+		b.reservedLabelReporter = report
+	}
+}
+
+// handleReservedLabels renames or removes the labels whose name is in reserved, according to the policy of the [Builder].
+func (b *Builder) handleReservedLabels(reserved []string) {
+	for i := 0; i < len(b.labels); i++ {
+		span := b.labels[i]
+		name := string(b.buf[span.start+1 : span.nameEnd])
+		if !slices.Contains(reserved, name) {
+			continue
+		}
+		metric := b.buf[:b.metricNameEnd()]
+		if b.reservedLabelPolicy == ReservedLabelPanic {
+			panic(fmt.Sprintf("vimebu: metric %q, label name %q is reserved", metric, name))
+		}
+		if b.reservedLabelPolicy == ReservedLabelDrop {
+			b.dropReservedLabel(i, name, "is reserved")
+			i--
+			continue
+		}
+		renamed := reservedLabelRenamePrefix + name
+		if b.labelIndex(renamed) >= 0 {
+			b.dropReservedLabel(i, name, fmt.Sprintf("is reserved and %q already exists", renamed))
+			i--
+			continue
+		}
+		// The closing bracket counts in the series length.
+		if b.maxSeriesLen > 0 && len(b.buf)+len(reservedLabelRenamePrefix)+1 > b.maxSeriesLen {
+			if b.limitPolicy == LimitPanic {
+				panic(fmt.Sprintf("vimebu: metric %q, label name %q, series len exceeds set limit of %d", metric, renamed, b.maxSeriesLen))
+			}
+			b.dropReservedLabel(i, name, fmt.Sprintf("is reserved and renaming it to %q exceeds the series len limit of %d", renamed, b.maxSeriesLen))
+			i--
+			continue
+		}
+		if b.reservedLabelReporter != nil {
+			b.reservedLabelReporter(string(metric), name)
+		} else {
+			log.Printf("vimebu: metric %q, label name %q is reserved - renaming to %q", metric, name, renamed)
+		}
+		b.renameLabelAt(i, renamed)
+	}
+}
+
+// dropReservedLabel reports and removes the i-th label, whose name is reserved.
+func (b *Builder) dropReservedLabel(i int, name, reason string) {
+	metric := b.buf[:b.metricNameEnd()]
+	if b.reservedLabelReporter != nil {
+		b.reservedLabelReporter(string(metric), name)
+	} else {
+		log.Printf("vimebu: metric %q, label name %q %s - skipping", metric, name, reason)
+	}
+	b.removeLabelAt(i)
+}
@@ -0,0 +1,84 @@
+package vimebu
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderReservedLabels(t *testing.T) {
+	set := metrics.NewSet()
+
+	logLines := captureLogOutput(func() {
+		Metric("request_duration_seconds").LabelString("le", "10").LabelString("path", "/").GetOrCreateHistogramInSet(set).Update(1)
+		Metric("request_size_bytes").LabelString("quantile", "high").GetOrCreateSummaryInSet(set).Update(1)
+		Metric("requests_total").LabelString("le", "10").GetOrCreateCounterInSet(set).Inc()
+	})
+	require.Len(t, logLines, 2)
+	require.Contains(t, logLines[0], `metric "request_duration_seconds", label name "le" is reserved - renaming to "exported_le"`)
+
+	out := writeSet(set)
+	require.Contains(t, out, `request_duration_seconds_count{exported_le="10",path="/"} 1`)
+	require.Contains(t, out, `request_size_bytes_count{exported_quantile="high"} 1`)
+	require.Contains(t, out, `requests_total{le="10"} 1`)
+}
+
+func TestBuilderOptionsWithReservedLabelPolicy(t *testing.T) {
+	set := metrics.NewSet()
+	var reported []string
+	report := WithReservedLabelReporter(func(metric, label string) {
+		reported = append(reported, metric+"/"+label)
+	})
+
+	logLines := captureLogOutput(func() {
+		Metric("first_seconds", WithReservedLabelPolicy(ReservedLabelDrop), report).
+			LabelString("vmrange", "1").
+			LabelString("path", "/").
+			LabelString("le", "2").
+			GetOrCreateHistogramInSet(set).
+			Update(1)
+		Metric("only_seconds", WithReservedLabelPolicy(ReservedLabelDrop), report).
+			LabelString("le", "1").
+			GetOrCreateHistogramInSet(set).
+			Update(1)
+	})
+	require.Empty(t, logLines)
+	require.Equal(t, []string{"first_seconds/vmrange", "first_seconds/le", "only_seconds/le"}, reported)
+
+	out := writeSet(set)
+	require.Contains(t, out, `first_seconds_count{path="/"} 1`)
+	require.Contains(t, out, "only_seconds_count 1")
+
+	require.PanicsWithValue(t, `vimebu: metric "size_bytes", label name "quantile" is reserved`, func() {
+		Metric("size_bytes", WithReservedLabelPolicy(ReservedLabelPanic)).LabelString("quantile", "1").GetOrCreateSummaryInSet(set)
+	})
+}
+
+func TestBuilderReservedLabelsRenameConflicts(t *testing.T) {
+	set := metrics.NewSet()
+
+	logLines := captureLogOutput(func() {
+		Metric("collision_seconds").
+			LabelString("exported_le", "1").
+			LabelString("le", "2").
+			GetOrCreateHistogramInSet(set).
+			Update(1)
+		Metric("h2", WithMaxSeriesLen(12)).
+			LabelString("le", "2").
+			GetOrCreateHistogramInSet(set).
+			Update(1)
+	})
+	require.Len(t, logLines, 2)
+	require.Contains(t, logLines[0], `metric "collision_seconds", label name "le" is reserved and "exported_le" already exists - skipping`)
+	require.Contains(t, logLines[1], `metric "h2", label name "le" is reserved and renaming it to "exported_le" exceeds the series len limit of 12 - skipping`)
+
+	out := writeSet(set)
+	require.Contains(t, out, `collision_seconds_count{exported_le="1"} 1`)
+	require.Contains(t, out, "h2_count 1")
+	require.NotContains(t, out, `h2_count{`)
+
+	require.PanicsWithValue(t, `vimebu: metric "h3", label name "exported_le", series len exceeds set limit of 12`, func() {
+		Metric("h3", WithMaxSeriesLen(12), WithLimitPolicy(LimitPanic)).LabelString("le", "2").GetOrCreateHistogramInSet(set)
+	})
+}
@@ -0,0 +1,238 @@
+package vimebu
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+const (
+	defaultAttrGroupSeparator  string = "_"
+	defaultCountingHandlerName string = "log_records_total"
+
+	levelLabelName string = "level"
+)
+
+// WithAttrGroupSeparator sets the separator used by [Builder.LabelAttrs] to join the keys
+// of nested [slog.Attr] groups into label names.
+//
+// Defaults to "_".
+func WithAttrGroupSeparator(sep string) BuilderOption {
+	return func(b *Builder) {
+		b.attrGroupSep = sep
+	}
+}
+
+// LabelAttrs adds a label for each of the provided [slog.Attr] to the [Builder].
+//
+// Values implementing [slog.LogValuer] are resolved. Groups are flattened, the label name
+// being the keys of the group and of the attribute joined with the separator set using
+// [WithAttrGroupSeparator]. Quotes inside label values will be escaped using [strconv.AppendQuote].
+//
+// Durations are formatted using [time.Duration.String], times using the [time.RFC3339Nano] layout,
+// and other values using their Error or String method if any, or [fmt.Sprint].
+//
+// Attributes with an empty key and value are skipped.
+//
+// Panics if [Builder.Metric] hasn't been called on this instance of the [Builder].
+func (b *Builder) LabelAttrs(attrs ...slog.Attr) *Builder {
+	for _, attr := range attrs {
+		b.labelAttr("", attr)
+	}
+	return b
+}
+
+func (b *Builder) labelAttr(prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	name := attr.Key
+	if len(prefix) > 0 {
+		if len(name) > 0 {
+			sep := b.attrGroupSep
+			if len(sep) == 0 {
+				sep = defaultAttrGroupSeparator
+			}
+			name = prefix + sep + name
+		} else {
+			name = prefix
+		}
+	}
+	switch value.Kind() {
+	case slog.KindGroup:
+		for _, groupAttr := range value.Group() {
+			b.labelAttr(name, groupAttr)
+		}
+	case slog.KindString:
+		b.LabelStringQuote(name, value.String())
+	case slog.KindInt64:
+		b.LabelInt64(name, value.Int64())
+	case slog.KindUint64:
+		b.LabelUint64(name, value.Uint64())
+	case slog.KindFloat64:
+		b.LabelFloat64(name, value.Float64())
+	case slog.KindBool:
+		b.LabelBool(name, value.Bool())
+	case slog.KindDuration:
+		b.LabelStringQuote(name, value.Duration().String())
+	case slog.KindTime:
+		b.LabelStringQuote(name, value.Time().Format(time.RFC3339Nano))
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case nil:
+			if len(name) > 0 {
+				b.LabelStringQuote(name, "")
+			}
+		case error:
+			b.LabelNamedErrorQuote(name, v)
+		case fmt.Stringer:
+			b.LabelStringerQuote(name, v)
+		default:
+			b.LabelStringQuote(name, fmt.Sprint(v))
+		}
+	}
+}
+
+// CountingHandlerOption represents a modifier function that will apply a specific
+// configuration to the [slog.Handler] returned by [NewCountingHandler].
+type CountingHandlerOption func(*countingHandlerConfig)
+
+type countingHandlerConfig struct {
+	set      *metrics.Set
+	name     string
+	attrKeys []string
+}
+
+// WithCountingHandlerSet sets the [metrics.Set] in which the counters will be registered.
+//
+// Defaults to the default [metrics.Set].
+func WithCountingHandlerSet(set *metrics.Set) CountingHandlerOption {
+	return func(c *countingHandlerConfig) {
+		c.set = set
+	}
+}
+
+// WithCountingHandlerMetricName sets the name of the counter.
+//
+// Defaults to "log_records_total".
+func WithCountingHandlerMetricName(name string) CountingHandlerOption {
+	return func(c *countingHandlerConfig) {
+		c.name = name
+	}
+}
+
+// WithCountingHandlerAttrKeys sets the keys of the attributes added as labels to the counter,
+// in addition to the level. See [Builder.LabelAttrs] for how their values are formatted.
+//
+// Attributes are looked up in the record first, then in the attributes added to the handler
+// using [slog.Handler.WithAttrs]. Attributes nested in a group aren't looked up.
+//
+// The values of these attributes must have a bounded cardinality.
+func WithCountingHandlerAttrKeys(keys ...string) CountingHandlerOption {
+	return func(c *countingHandlerConfig) {
+		c.attrKeys = keys
+	}
+}
+
+// NewCountingHandler wraps a [slog.Handler], counting the records it handles by level
+// (e.g. log_records_total{level="info"}) before passing them to next.
+//
+// Panics if next is nil, or if the metric name is empty.
+func NewCountingHandler(next slog.Handler, options ...CountingHandlerOption) slog.Handler {
+	if next == nil {
+		panic("vimebu: NewCountingHandler has been passed a nil handler")
+	}
+	cfg := countingHandlerConfig{
+		set:  metrics.GetDefaultSet(),
+		name: defaultCountingHandlerName,
+	}
+	for _, applyOption := range options {
+		applyOption(&cfg)
+	}
+	if len(cfg.name) == 0 {
+		panic("vimebu: NewCountingHandler has been passed an empty metric name")
+	}
+	return &countingHandler{next: next, cfg: &cfg}
+}
+
+type countingHandler struct {
+	next slog.Handler
+	cfg  *countingHandlerConfig
+
+	// attrs holds the attributes added using WithAttrs before any group was opened.
+	attrs   []slog.Attr
+	grouped bool
+}
+
+func (h *countingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *countingHandler) Handle(ctx context.Context, r slog.Record) error {
+	b := Metric(h.cfg.name).LabelString(levelLabelName, levelLabelValue(r.Level))
+	for _, key := range h.cfg.attrKeys {
+		if attr, ok := h.lookupAttr(r, key); ok {
+			b.labelAttr("", attr)
+		}
+	}
+	b.GetOrCreateCounterInSet(h.cfg.set).Inc()
+	return h.next.Handle(ctx, r)
+}
+
+func (h *countingHandler) lookupAttr(r slog.Record, key string) (slog.Attr, bool) {
+	var (
+		found slog.Attr
+		ok    bool
+	)
+	if !h.grouped {
+		r.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == key {
+				found, ok = attr, true
+			}
+			return !ok
+		})
+		if ok {
+			return found, true
+		}
+	}
+	for i := len(h.attrs) - 1; i >= 0; i-- {
+		if h.attrs[i].Key == key {
+			return h.attrs[i], true
+		}
+	}
+	return found, false
+}
+
+func (h *countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		clone.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)
+	}
+	return &clone
+}
+
+func (h *countingHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.grouped = clone.grouped || len(name) > 0
+	return &clone
+}
+
+// levelLabelValue returns the lower cased name of a [slog.Level], without allocating for the standard levels.
+func levelLabelValue(level slog.Level) string {
+	switch level {
+	case slog.LevelDebug:
+		return "debug"
+	case slog.LevelInfo:
+		return "info"
+	case slog.LevelWarn:
+		return "warn"
+	case slog.LevelError:
+		return "error"
+	default:
+		return strings.ToLower(level.String())
+	}
+}
@@ -0,0 +1,72 @@
+package vimebu
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+type userValuer struct {
+	id   int
+	name string
+}
+
+func (u userValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.id), slog.String("name", u.name))
+}
+
+func TestBuilderLabelAttrs(t *testing.T) {
+	metric := Metric("test_attrs").LabelAttrs(
+		slog.String("path", `/"quoted"`),
+		slog.Int("attempt", 3),
+		slog.Uint64("size", 42),
+		slog.Float64("ratio", 0.5),
+		slog.Bool("cached", true),
+		slog.Duration("timeout", 1500*time.Millisecond),
+		slog.Time("at", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		slog.Any("err", errors.New("boom")),
+		slog.Any("user", userValuer{id: 1, name: "gopher"}),
+		slog.Group("http", slog.String("method", "GET"), slog.Group("", slog.Int("status", 200))),
+		slog.Attr{},
+	).String()
+
+	require.Equal(t, `test_attrs{path="/\"quoted\"",attempt="3",size="42",ratio="0.5",cached="true",timeout="1.5s",at="2024-01-02T03:04:05Z",err="boom",user_id="1",user_name="gopher",http_method="GET",http_status="200"}`, metric)
+}
+
+func TestBuilderLabelAttrsGroupSeparator(t *testing.T) {
+	metric := Metric("test_attrs", WithAttrGroupSeparator("__")).LabelAttrs(slog.Group("db", slog.String("system", "postgres"))).String()
+	require.Equal(t, `test_attrs{db__system="postgres"}`, metric)
+}
+
+func TestCountingHandler(t *testing.T) {
+	set := metrics.NewSet()
+	var buf bytes.Buffer
+	handler := NewCountingHandler(
+		slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		WithCountingHandlerSet(set),
+		WithCountingHandlerAttrKeys("component", "code"),
+	)
+	logger := slog.New(handler)
+
+	logger.Debug("not enabled")
+	logger.Info("hello")
+	logger.Info("hello", "code", 200)
+	dbLogger := logger.With("component", "db")
+	dbLogger.Error("failed", "code", 500)
+	dbLogger.Error("failed", "component", "cache", "code", 500)
+	dbLogger.WithGroup("query").Warn("slow", "code", 1)
+
+	out := writeSet(set)
+	require.NotContains(t, out, `level="debug"`)
+	require.Contains(t, out, `log_records_total{level="info"} 1`)
+	require.Contains(t, out, `log_records_total{level="info",code="200"} 1`)
+	require.Contains(t, out, `log_records_total{level="error",component="db",code="500"} 1`)
+	require.Contains(t, out, `log_records_total{level="error",component="cache",code="500"} 1`)
+	require.Contains(t, out, `log_records_total{level="warn",component="db"} 1`)
+	require.Contains(t, buf.String(), "msg=slow component=db query.code=1")
+}
@@ -0,0 +1,478 @@
+package vimebu
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+const (
+	defaultSQLPrefix string = "sql"
+
+	sqlDriverLabelName    string = "driver"
+	sqlOperationLabelName string = "operation"
+	sqlQueryLabelName     string = "query"
+
+	sqlOtherQuery string = "other"
+)
+
+// Operations recorded by the drivers returned by [WrapDriver] and [WrapConnector].
+const (
+	SQLOperationQuery    string = "query"
+	SQLOperationExec     string = "exec"
+	SQLOperationPrepare  string = "prepare"
+	SQLOperationBegin    string = "begin"
+	SQLOperationCommit   string = "commit"
+	SQLOperationRollback string = "rollback"
+)
+
+// SQLOption represents a modifier function that will apply a specific
+// configuration to the drivers returned by [WrapDriver] and [WrapConnector].
+type SQLOption func(*sqlConfig)
+
+type sqlConfig struct {
+	set       *metrics.Set
+	prefix    string
+	queryName func(query string) string
+}
+
+// WithSQLSet sets the [metrics.Set] in which the metrics will be registered.
+//
+// Defaults to the default [metrics.Set].
+func WithSQLSet(set *metrics.Set) SQLOption {
+	return func(c *sqlConfig) {
+		c.set = set
+	}
+}
+
+// WithSQLPrefix sets the prefix of the recorded metric names.
+//
+// Defaults to "sql".
+func WithSQLPrefix(prefix string) SQLOption {
+	return func(c *sqlConfig) {
+		c.prefix = prefix
+	}
+}
+
+// WithSQLQueryNameExtractor sets the function used to extract the value of the query label from a SQL query.
+//
+// It must return values with a bounded cardinality, the query label is skipped if it returns an empty string.
+//
+// Defaults to [SQLQueryVerb].
+func WithSQLQueryNameExtractor(extract func(query string) string) SQLOption {
+	return func(c *sqlConfig) {
+		c.queryName = extract
+	}
+}
+
+// SQLQueryVerb returns the first keyword of a SQL query in lower case (e.g. "select"), skipping the leading
+// comments such as the "-- name: ..." annotations of query generators, or "other" if the query doesn't start
+// with a keyword.
+func SQLQueryVerb(query string) string {
+	query = sqlTrimLeadingComments(query)
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end < 0 {
+		end = len(query)
+	}
+	if end == 0 {
+		return sqlOtherQuery
+	}
+	return strings.ToLower(query[:end])
+}
+
+// sqlTrimLeadingComments removes the spaces, line comments and block comments preceding the first statement of query.
+func sqlTrimLeadingComments(query string) string {
+	for {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query[2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[2+end+2:]
+		default:
+			return query
+		}
+	}
+}
+
+// WrapDriver wraps a [driver.Driver], recording the following metrics for each operation sent to the database :
+//
+//   - <prefix>_operations_total{driver="...",operation="...",query="..."} : counter of operations
+//   - <prefix>_operation_duration_seconds{driver="...",operation="...",query="..."} : histogram of the operations duration
+//   - <prefix>_errors_total{driver="...",operation="...",query="..."} : counter of failed operations
+//
+// The operation label holds one of the SQLOperation* values, and the query label is extracted from
+// the SQL query using the function set with [WithSQLQueryNameExtractor]. It is omitted for operations
+// without a query, such as transaction commits.
+//
+// The returned driver is meant to be registered using [sql.Register] :
+//
+//	sql.Register("postgres-instrumented", vimebu.WrapDriver("postgres", &pq.Driver{}))
+//
+// Panics if driverName or the metric prefix are empty.
+func WrapDriver(driverName string, d driver.Driver, options ...SQLOption) driver.Driver {
+	return &sqlDriver{Driver: d, rec: newSQLRecorder(driverName, options)}
+}
+
+// WrapConnector works like [WrapDriver], for a [driver.Connector] meant to be used with [sql.OpenDB].
+//
+// Panics if driverName or the metric prefix are empty.
+func WrapConnector(driverName string, c driver.Connector, options ...SQLOption) driver.Connector {
+	rec := newSQLRecorder(driverName, options)
+	return &sqlConnector{
+		connector: c,
+		driver:    &sqlDriver{Driver: c.Driver(), rec: rec},
+		rec:       rec,
+	}
+}
+
+type sqlRecorder struct {
+	set        *metrics.Set
+	driverName string
+	queryName  func(query string) string
+
+	operationsName string
+	durationName   string
+	errorsName     string
+}
+
+func newSQLRecorder(driverName string, options []SQLOption) *sqlRecorder {
+	cfg := sqlConfig{
+		set:       metrics.GetDefaultSet(),
+		prefix:    defaultSQLPrefix,
+		queryName: SQLQueryVerb,
+	}
+	for _, applyOption := range options {
+		applyOption(&cfg)
+	}
+	if len(driverName) == 0 {
+		panic("vimebu: WrapDriver has been passed an empty driver name")
+	}
+	if len(cfg.prefix) == 0 {
+		panic("vimebu: WrapDriver has been passed an empty metric prefix")
+	}
+	return &sqlRecorder{
+		set:            cfg.set,
+		driverName:     driverName,
+		queryName:      cfg.queryName,
+		operationsName: cfg.prefix + "_operations_total",
+		durationName:   cfg.prefix + "_operation_duration_seconds",
+		errorsName:     cfg.prefix + "_errors_total",
+	}
+}
+
+func (r *sqlRecorder) observe(operation, query string, start time.Time, err error) {
+	// The operation will be retried by database/sql using another code path, which will be recorded.
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	duration := time.Since(start).Seconds()
+	var name string
+	if len(query) > 0 && r.queryName != nil {
+		name = r.queryName(query)
+	}
+	r.metric(r.operationsName, operation, name).GetOrCreateCounterInSet(r.set).Inc()
+	r.metric(r.durationName, operation, name).GetOrCreateHistogramInSet(r.set).Update(duration)
+	if err != nil {
+		r.metric(r.errorsName, operation, name).GetOrCreateCounterInSet(r.set).Inc()
+	}
+}
+
+func (r *sqlRecorder) metric(metric, operation, name string) *Builder {
+	b := Metric(metric).
+		LabelString(sqlDriverLabelName, r.driverName).
+		LabelString(sqlOperationLabelName, operation)
+	if len(name) > 0 {
+		b.LabelStringQuote(sqlQueryLabelName, name)
+	}
+	return b
+}
+
+type sqlDriver struct {
+	driver.Driver
+	rec *sqlRecorder
+}
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn: conn, rec: d.rec}, nil
+}
+
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlConnector{connector: c, driver: d, rec: d.rec}, nil
+	}
+	return &sqlConnector{connector: sqlDSNConnector{dsn: name, driver: d.Driver}, driver: d, rec: d.rec}, nil
+}
+
+// sqlDSNConnector is a [driver.Connector] for drivers not implementing [driver.DriverContext].
+type sqlDSNConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c sqlDSNConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c sqlDSNConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type sqlConnector struct {
+	connector driver.Connector
+	driver    driver.Driver
+	rec       *sqlRecorder
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn: conn, rec: c.rec}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type sqlConn struct {
+	conn driver.Conn
+	rec  *sqlRecorder
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if cp, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = cp.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	c.rec.observe(SQLOperationPrepare, query, start, err)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := &sqlStmt{stmt: stmt, conn: c, query: query}
+	if cc, ok := stmt.(driver.ColumnConverter); ok {
+		return &sqlColumnConverterStmt{sqlStmt: wrapped, cc: cc}, nil
+	}
+	return wrapped, nil
+}
+
+func (c *sqlConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if cb, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = cb.BeginTx(ctx, opts)
+	} else if opts.Isolation != driver.IsolationLevel(0) {
+		err = errors.New("sql: driver does not support non-default isolation level")
+	} else if opts.ReadOnly {
+		err = errors.New("sql: driver does not support read-only transactions")
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	c.rec.observe(SQLOperationBegin, "", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx, rec: c.rec}, nil
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	c.rec.observe(SQLOperationExec, query, start, err)
+	return res, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.rec.observe(SQLOperationQuery, query, start, err)
+	return rows, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	stmt  driver.Stmt
+	conn  *sqlConn
+	query string
+}
+
+func (s *sqlStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *sqlStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	res, err := s.stmt.Exec(args)
+	s.conn.rec.observe(SQLOperationExec, s.query, start, err)
+	return res, err
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.stmt.Query(args)
+	s.conn.rec.observe(SQLOperationQuery, s.query, start, err)
+	return rows, err
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := sqlNamedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Exec(values)
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, args)
+	s.conn.rec.observe(SQLOperationExec, s.query, start, err)
+	return res, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := sqlNamedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Query(values)
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	s.conn.rec.observe(SQLOperationQuery, s.query, start, err)
+	return rows, err
+}
+
+// CheckNamedValue mimics database/sql, which checks the statement before falling back to the connection.
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+// sqlColumnConverterStmt forwards [driver.ColumnConverter], only implemented by the statements wrapping one
+// so that database/sql keeps converting the arguments of the other statements using its default converter.
+type sqlColumnConverterStmt struct {
+	*sqlStmt
+	cc driver.ColumnConverter
+}
+
+func (s *sqlColumnConverterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.cc.ColumnConverter(idx)
+}
+
+func sqlNamedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if len(arg.Name) > 0 {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+type sqlTx struct {
+	tx  driver.Tx
+	rec *sqlRecorder
+}
+
+func (t *sqlTx) Commit() error {
+	start := time.Now()
+	err := t.tx.Commit()
+	t.rec.observe(SQLOperationCommit, "", start, err)
+	return err
+}
+
+func (t *sqlTx) Rollback() error {
+	start := time.Now()
+	err := t.tx.Rollback()
+	t.rec.observe(SQLOperationRollback, "", start, err)
+	return err
+}
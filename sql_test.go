@@ -0,0 +1,206 @@
+package vimebu
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver is a minimal in-memory driver, failing every query containing "fail".
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "convert") {
+		return fakeConverterStmt{fakeStmt{query: query}}, nil
+	}
+	return fakeStmt{query: query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("exec failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errors.New("query failed")
+	}
+	return &fakeRows{}, nil
+}
+
+// fakeConverterStmt converts every argument to its length, and fails if it receives unconverted arguments.
+type fakeConverterStmt struct {
+	fakeStmt
+}
+
+func (fakeConverterStmt) ColumnConverter(int) driver.ValueConverter {
+	return fakeLenConverter{}
+}
+
+func (fakeConverterStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, ok := args[0].(int64); !ok {
+		return nil, errors.New("unconverted argument")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeLenConverter struct{}
+
+func (fakeLenConverter) ConvertValue(v any) (driver.Value, error) {
+	return int64(len(v.(string))), nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (*fakeRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (*fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(42)
+	return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+func TestWrapDriver(t *testing.T) {
+	set := metrics.NewSet()
+	// The driver is opened without registering it, as drivers can't be unregistered between test runs.
+	connector, err := WrapDriver("fake", fakeDriver{}, WithSQLSet(set)).(driver.DriverContext).OpenConnector("")
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	var value int
+	require.NoError(t, db.QueryRow("SELECT value FROM things").Scan(&value))
+	require.Equal(t, 42, value)
+	_, err = db.Query("select fail")
+	require.Error(t, err)
+	_, err = db.Exec("INSERT INTO things VALUES (1)")
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE fail")
+	require.Error(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	out := writeSet(set)
+	// Queries go through prepared statements, as the connection doesn't implement driver.QueryerContext.
+	require.Contains(t, out, `sql_operations_total{driver="fake",operation="prepare",query="select"} 2`)
+	require.Contains(t, out, `sql_operations_total{driver="fake",operation="query",query="select"} 2`)
+	require.Contains(t, out, `sql_errors_total{driver="fake",operation="query",query="select"} 1`)
+	require.Contains(t, out, `sql_operations_total{driver="fake",operation="exec",query="insert"} 1`)
+	require.Contains(t, out, `sql_operations_total{driver="fake",operation="exec",query="update"} 1`)
+	require.Contains(t, out, `sql_errors_total{driver="fake",operation="exec",query="update"} 1`)
+	require.Contains(t, out, `sql_operations_total{driver="fake",operation="begin"} 1`)
+	require.Contains(t, out, `sql_operation_duration_seconds_count{driver="fake",operation="commit"} 1`)
+}
+
+func TestWrapConnectorQueryNameExtractor(t *testing.T) {
+	set := metrics.NewSet()
+	connector := WrapConnector("fake", sqlDSNConnector{driver: fakeDriver{}}, WithSQLSet(set), WithSQLPrefix("db"), WithSQLQueryNameExtractor(func(query string) string {
+		if strings.Contains(query, "things") {
+			return "things"
+		}
+		return ""
+	}))
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM things")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM others")
+	require.NoError(t, err)
+
+	out := writeSet(set)
+	require.Contains(t, out, `db_operations_total{driver="fake",operation="exec",query="things"} 1`)
+	require.Contains(t, out, `db_operations_total{driver="fake",operation="exec"} 1`)
+}
+
+func TestWrapConnectorColumnConverter(t *testing.T) {
+	db := sql.OpenDB(WrapConnector("fake", sqlDSNConnector{driver: fakeDriver{}}, WithSQLSet(metrics.NewSet())))
+	defer db.Close()
+
+	stmt, err := db.Prepare("INSERT INTO convert VALUES (?)")
+	require.NoError(t, err)
+	defer stmt.Close()
+	_, err = stmt.Exec("seven")
+	require.NoError(t, err)
+
+	// Statements without converter are left to the default conversion of database/sql.
+	conn := &sqlConn{conn: fakeConn{}, rec: newSQLRecorder("fake", []SQLOption{WithSQLSet(metrics.NewSet())})}
+	plain, err := conn.Prepare("INSERT INTO things VALUES (?)")
+	require.NoError(t, err)
+	_, ok := plain.(driver.ColumnConverter)
+	require.False(t, ok)
+}
+
+func TestSQLQueryVerb(t *testing.T) {
+	require.Equal(t, "select", SQLQueryVerb("  SELECT * FROM foo"))
+	require.Equal(t, "with", SQLQueryVerb("WITH cte AS (SELECT 1) SELECT * FROM cte"))
+	require.Equal(t, "begin", SQLQueryVerb("BEGIN"))
+	require.Equal(t, "select", SQLQueryVerb("-- name: GetThing :one\nSELECT * FROM things"))
+	require.Equal(t, "update", SQLQueryVerb("/* app:api */ /* trace */\n UPDATE things SET value = 1"))
+	require.Equal(t, "other", SQLQueryVerb("-- comment"))
+	require.Equal(t, "other", SQLQueryVerb("/* unterminated"))
+	require.Equal(t, "other", SQLQueryVerb("(SELECT 1)"))
+	require.Equal(t, "other", SQLQueryVerb(""))
+}
@@ -7,121 +7,277 @@ import (
 )
 
 // GetOrCreateCounter calls [metrics.GetOrCreateCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Counter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateCounter() *metrics.Counter {
-	return metrics.GetOrCreateCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopCounter()
+	}
+	return metrics.GetOrCreateCounter(name)
 }
 
 // GetOrCreateCounterInSet calls [metrics.Set.GetOrCreateCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Counter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateCounterInSet(set *metrics.Set) *metrics.Counter {
-	return set.GetOrCreateCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopCounter()
+	}
+	return set.GetOrCreateCounter(name)
 }
 
 // NewCounter calls [metrics.NewCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Counter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewCounter() *metrics.Counter {
-	return metrics.NewCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopCounter()
+	}
+	return metrics.NewCounter(name)
 }
 
 // NewCounterInSet calls [metrics.Set.NewCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Counter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewCounterInSet(set *metrics.Set) *metrics.Counter {
-	return set.NewCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopCounter()
+	}
+	return set.NewCounter(name)
 }
 
 // GetOrCreateFloatCounter calls [metrics.GetOrCreateFloatCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.FloatCounter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateFloatCounter() *metrics.FloatCounter {
-	return metrics.GetOrCreateFloatCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopFloatCounter()
+	}
+	return metrics.GetOrCreateFloatCounter(name)
 }
 
 // GetOrCreateFloatCounterInSet calls [metrics.Set.GetOrCreateFloatCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.FloatCounter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateFloatCounterInSet(set *metrics.Set) *metrics.FloatCounter {
-	return set.GetOrCreateFloatCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopFloatCounter()
+	}
+	return set.GetOrCreateFloatCounter(name)
 }
 
 // NewFloatCounter calls [metrics.NewFloatCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.FloatCounter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewFloatCounter() *metrics.FloatCounter {
-	return metrics.NewFloatCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopFloatCounter()
+	}
+	return metrics.NewFloatCounter(name)
 }
 
 // NewFloatCounterInSet calls [metrics.Set.NewFloatCounter] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.FloatCounter] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewFloatCounterInSet(set *metrics.Set) *metrics.FloatCounter {
-	return set.NewFloatCounter(b.String())
+	name, ok := b.build(nil)
+	if !ok {
+		return noopFloatCounter()
+	}
+	return set.NewFloatCounter(name)
 }
 
 // GetOrCreateHistogram calls [metrics.GetOrCreateHistogram] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Histogram] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateHistogram() *metrics.Histogram {
-	return metrics.GetOrCreateHistogram(b.String())
+	name, ok := b.build(histogramReservedLabels)
+	if !ok {
+		return noopHistogram()
+	}
+	return metrics.GetOrCreateHistogram(name)
 }
 
 // GetOrCreateHistogramInSet calls [metrics.Set.GetOrCreateHistogram] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Histogram] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateHistogramInSet(set *metrics.Set) *metrics.Histogram {
-	return set.GetOrCreateHistogram(b.String())
+	name, ok := b.build(histogramReservedLabels)
+	if !ok {
+		return noopHistogram()
+	}
+	return set.GetOrCreateHistogram(name)
 }
 
 // NewHistogram calls [metrics.NewHistogram] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Histogram] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewHistogram() *metrics.Histogram {
-	return metrics.NewHistogram(b.String())
+	name, ok := b.build(histogramReservedLabels)
+	if !ok {
+		return noopHistogram()
+	}
+	return metrics.NewHistogram(name)
 }
 
 // NewHistogramInSet calls [metrics.Set.NewHistogram] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Histogram] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewHistogramInSet(set *metrics.Set) *metrics.Histogram {
-	return set.NewHistogram(b.String())
+	name, ok := b.build(histogramReservedLabels)
+	if !ok {
+		return noopHistogram()
+	}
+	return set.NewHistogram(name)
 }
 
 // GetOrCreateGauge calls [metrics.GetOrCreateGauge] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Gauge] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateGauge(f func() float64) *metrics.Gauge {
-	return metrics.GetOrCreateGauge(b.String(), f)
+	name, ok := b.build(nil)
+	if !ok {
+		return noopGauge()
+	}
+	return metrics.GetOrCreateGauge(name, f)
 }
 
 // GetOrCreateGaugeInSet calls [metrics.Set.GetOrCreateGauge] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Gauge] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateGaugeInSet(set *metrics.Set, f func() float64) *metrics.Gauge {
-	return set.GetOrCreateGauge(b.String(), f)
+	name, ok := b.build(nil)
+	if !ok {
+		return noopGauge()
+	}
+	return set.GetOrCreateGauge(name, f)
 }
 
 // NewGauge calls [metrics.NewGauge] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Gauge] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewGauge(f func() float64) *metrics.Gauge {
-	return metrics.NewGauge(b.String(), f)
+	name, ok := b.build(nil)
+	if !ok {
+		return noopGauge()
+	}
+	return metrics.NewGauge(name, f)
 }
 
 // NewGaugeInSet calls [metrics.Set.NewGauge] using the Builder's accumulated string as argument.
+//
+// Returns a no-op [metrics.Gauge] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewGaugeInSet(set *metrics.Set, f func() float64) *metrics.Gauge {
-	return set.NewGauge(b.String(), f)
+	name, ok := b.build(nil)
+	if !ok {
+		return noopGauge()
+	}
+	return set.NewGauge(name, f)
 }
 
 // GetOrCreateSummary calls [metrics.GetOrCreateSummary] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateSummary() *metrics.Summary {
-	return metrics.GetOrCreateSummary(b.String())
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return metrics.GetOrCreateSummary(name)
 }
 
 // GetOrCreateSummaryInSet calls [metrics.Set.GetOrCreateSummary] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateSummaryInSet(set *metrics.Set) *metrics.Summary {
-	return set.GetOrCreateSummary(b.String())
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return set.GetOrCreateSummary(name)
 }
 
 // NewSummary calls [metrics.NewSummary] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewSummary() *metrics.Summary {
-	return metrics.NewSummary(b.String())
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return metrics.NewSummary(name)
 }
 
 // NewSummaryInSet calls [metrics.Set.NewSummary] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewSummaryInSet(set *metrics.Set) *metrics.Summary {
-	return set.NewSummary(b.String())
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return set.NewSummary(name)
 }
 
 // GetOrCreateSummaryExt calls [metrics.GetOrCreateSummaryExt] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateSummaryExt(window time.Duration, quantiles []float64) *metrics.Summary {
-	return metrics.GetOrCreateSummaryExt(b.String(), window, quantiles)
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return metrics.GetOrCreateSummaryExt(name, window, quantiles)
 }
 
 // GetOrCreateSummaryExtInSet calls [metrics.Set.GetOrCreateSummaryExt] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) GetOrCreateSummaryExtInSet(set *metrics.Set, window time.Duration, quantiles []float64) *metrics.Summary {
-	return set.GetOrCreateSummaryExt(b.String(), window, quantiles)
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return set.GetOrCreateSummaryExt(name, window, quantiles)
 }
 
 // NewSummaryExt calls [metrics.NewSummaryExt] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewSummaryExt(window time.Duration, quantiles []float64) *metrics.Summary {
-	return metrics.NewSummaryExt(b.String(), window, quantiles)
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return metrics.NewSummaryExt(name, window, quantiles)
 }
 
 // NewSummaryExtInSet calls [metrics.Set.NewSummaryExtInSet] using the Builder's accumulated string as argument.
+//
+// Labels whose name is reserved for this metric type are handled according to [WithReservedLabelPolicy].
+// Returns a no-op [metrics.Summary] if the metric has been dropped, see [WithRelabelConfigs] and [BuilderPool.SetPolicy].
 func (b *Builder) NewSummaryExtInSet(set *metrics.Set, window time.Duration, quantiles []float64) *metrics.Summary {
-	return set.NewSummaryExt(b.String(), window, quantiles)
+	name, ok := b.build(summaryReservedLabels)
+	if !ok {
+		return noopSummary()
+	}
+	return set.NewSummaryExt(name, window, quantiles)
 }